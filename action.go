@@ -1,11 +1,30 @@
 package workflow
 
 import (
+	"context"
+	"reflect"
 	"sync"
 )
 
-// A simple function definition with a single input and output.
-type Action func(any) (any, error)
+// A unit of work with a single input and output.
+type Action interface {
+	Run(ctx context.Context, in any) (any, error)
+}
+
+// Adapts an ordinary function into an action.
+type ActionFunc func(ctx context.Context, in any) (any, error)
+
+// Calls f(ctx, in).
+func (f ActionFunc) Run(ctx context.Context, in any) (any, error) {
+	return f(ctx, in)
+}
+
+// Adapts a function written for the Action type before it took a context, so existing actions keep working. The context is ignored. New actions should use ActionFunc, Do or DoContext instead.
+func Func(f func(in any) (any, error)) Action {
+	return ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return f(in)
+	})
+}
 
 // Contains an action output and associated error, if any.
 type Result struct {
@@ -15,10 +34,33 @@ type Result struct {
 
 // Encapsulate a function with types into an action.
 func Do[T1 any, T2 any](action func(T1) (T2, error)) Action {
-	return func(in any) (any, error) {
+	return newStep(KindAction, nil, func(ctx context.Context, in any) (any, error) {
 		input := in.(T1)
 		return action(input)
+	})
+}
+
+// Encapsulate a function with types that also accepts a context into an action.
+func DoContext[T1 any, T2 any](action func(context.Context, T1) (T2, error)) Action {
+	return newStep(KindAction, nil, func(ctx context.Context, in any) (any, error) {
+		input := in.(T1)
+		return action(ctx, input)
+	})
+}
+
+// Gives an action a name that will be reported when the action executes.
+func Name(name string, action Action) Action {
+	if action == nil {
+		action = NoOp()
+	}
+	if s, ok := action.(*step); ok {
+		named := *s
+		named.name = name
+		return &named
 	}
+	s := newStep(KindAction, nil, action.Run)
+	s.name = name
+	return s
 }
 
 // Combines multiple actions into a single action that will execute based on the order the actions were passed.
@@ -27,36 +69,85 @@ func Sequential(actions ...Action) Action {
 		return NoOp()
 	}
 
-	sequential := actions[0]
+	sequential := indexed(0, actions[0])
 	for i := 1; i < len(actions); i++ {
-		sequential = wrap(sequential, actions[i])
+		sequential = wrap(sequential, indexed(i, actions[i]))
 	}
 
-	return sequential
+	return newStep(KindSequential, actions, sequential.Run)
+}
+
+// Options for configuring a parallel step.
+type ParallelOptions struct {
+	// Copies the input for each action, so actions can modify their input without racing each other. Can set to DeepCopy, which uses Clone for values that implement Cloner. Can set to nil to pass the same input to every action.
+	Clone func(in any) any
 }
 
 // Execute multiple actions in parallel. The reduce function should combine all parallel results into a single result.
+//
+// Each action receives a child of the state, and the values each action sets are merged into the state once every action has completed.
 func Parallel[T any](reduce func(in []Result) (T, error), actions ...Action) Action {
-	return func(in any) (any, error) {
-		var outputs []Result
+	return ParallelWithOptions(nil, reduce, actions...)
+}
+
+// Same as Parallel, but configured with options. If opts is nil, every action receives the same input.
+func ParallelWithOptions[T any](opts *ParallelOptions, reduce func(in []Result) (T, error), actions ...Action) Action {
+	var clone func(in any) any
+	if opts != nil {
+		clone = opts.Clone
+	}
+
+	return newStep(KindParallel, actions, func(ctx context.Context, in any) (any, error) {
+		var original any
+		mutation := detectMutation(ctx)
+		if mutation {
+			original = DeepCopy(in)
+		}
+
+		state := StateFromContext(ctx)
+		branches := make([]*State, len(actions))
+		results := make([]Result, len(actions))
+		var order []int
 		var lock sync.Mutex
 		var wg sync.WaitGroup
-		for _, v := range actions {
+		for i, v := range actions {
+			branches[i] = state.child()
+			input := in
+			if clone != nil {
+				input = clone(in)
+			}
 			wg.Add(1)
 			go func(in any) {
 				defer wg.Done()
-				out, err := v(in)
+				out, err := runChild(WithState(ctx, branches[i]), i, v, in)
 				lock.Lock()
 				defer lock.Unlock()
-				outputs = append(outputs, Result{
+				results[i] = Result{
 					Out: out,
 					Err: err,
-				})
-			}(in)
+				}
+				order = append(order, i)
+			}(input)
 		}
 		wg.Wait()
+
+		if mutation && !deepEqual(reflect.ValueOf(original), reflect.ValueOf(in), map[[2]uintptr]bool{}) {
+			return nil, ErrInputMutated
+		}
+
+		if err := state.mergeChildren(branches); err != nil {
+			return nil, err
+		}
+
+		// outputs are in the order the actions completed
+		order = parallelOrder(ctx, order)
+		var outputs []Result
+		for _, v := range order {
+			outputs = append(outputs, results[v])
+		}
+		hooksFromContext(ctx).parallelComplete(ctx, outputs)
 		return reduce(outputs)
-	}
+	})
 }
 
 // Conditionally execute another action. Only one action will be executed.
@@ -66,44 +157,55 @@ func If[T any](condition func(in T) (bool, error), ifTrue Action, ifFalse Action
 		return NoOp()
 	}
 
-	return func(in any) (any, error) {
+	return newStep(KindIf, []Action{ifTrue, ifFalse}, func(ctx context.Context, in any) (any, error) {
 		input := in.(T)
 		condition, err := condition(input)
 		if err != nil {
 			return nil, err
 		}
+		hooksFromContext(ctx).branch(ctx, condition)
 		if condition {
-			return ifTrue(in)
+			return runChild(ctx, 0, ifTrue, in)
 		} else {
-			return ifFalse(in)
+			return runChild(ctx, 1, ifFalse, in)
 		}
-	}
+	})
 }
 
 // Executes an action and calls the handle function if an error occurs.
 func Catch(action Action, handle func(any, error) (any, error)) Action {
-	return func(in any) (any, error) {
-		out, err := action(in)
+	return newStep(KindCatch, []Action{action}, func(ctx context.Context, in any) (any, error) {
+		out, err := runChild(ctx, 0, action, in)
 		if err != nil {
 			return handle(out, err)
 		}
 		return out, nil
-	}
+	})
 }
 
 // Executes an action and then, regardless of whether an error occurred, calls the finally function.
 func Finally(action Action, finally func(any, error) (any, error)) Action {
-	return func(in any) (any, error) {
-		out, err := action(in)
+	return newStep(KindFinally, []Action{action}, func(ctx context.Context, in any) (any, error) {
+		out, err := runChild(ctx, 0, action, in)
 		return finally(out, err)
-	}
+	})
 }
 
 // Returns an action that does nothing and returns nil.
 func NoOp() Action {
-	return func(in any) (any, error) {
+	return newStep(KindNoOp, nil, func(ctx context.Context, in any) (any, error) {
 		return nil, nil
+	})
+}
+
+// Wraps an action so it runs as the child of a step at the given index.
+func indexed(index int, action Action) Action {
+	if action == nil {
+		return nil
 	}
+	return ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return runChild(ctx, index, action, in)
+	})
 }
 
 // Wraps provided actions so that "action" is called first and then "next" is called.
@@ -117,11 +219,11 @@ func wrap(action Action, next Action) Action {
 	if next == nil {
 		return action
 	}
-	return func(in any) (any, error) {
-		out, err := action(in)
+	return ActionFunc(func(ctx context.Context, in any) (any, error) {
+		out, err := action.Run(ctx, in)
 		if err != nil {
 			return out, err
 		}
-		return next(out)
-	}
+		return next.Run(ctx, out)
+	})
 }
@@ -1,6 +1,7 @@
 package workflow
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -11,13 +12,70 @@ import (
 func Test_Unit_Action_NoOp(t *testing.T) {
 	// act
 	action := NoOp()
-	out, err := action(1)
+	out, err := action.Run(context.Background(), 1)
 
 	// assert
 	assert.NoError(t, err)
 	assert.Nil(t, out)
 }
 
+func Test_Unit_Action_DoContext(t *testing.T) {
+	// arrange
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, 2)
+
+	// act
+	action := DoContext(func(ctx context.Context, in int) (int, error) {
+		return in + ctx.Value(key{}).(int), nil
+	})
+	out, err := action.Run(ctx, 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, out)
+}
+
+func Test_Unit_Action_Func(t *testing.T) {
+	// arrange
+	legacy := func(in any) (any, error) {
+		return in.(int) + 1, nil
+	}
+
+	// act
+	action := Sequential(Func(legacy), Func(legacy))
+	out, err := action.Run(context.Background(), 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, out)
+}
+
+func Test_Unit_Action_Name(t *testing.T) {
+	// arrange
+	var names []string
+	ctx := WithHooks(context.Background(), &Hooks{
+		OnStepStart: func(ctx context.Context, step StepInfo, in any) context.Context {
+			names = append(names, step.Name+" "+string(step.Kind))
+			return ctx
+		},
+	})
+	add1 := Do(func(in int) (int, error) {
+		return in + 1, nil
+	})
+	handWritten := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return in.(int) + 2, nil
+	})
+
+	// act
+	action := Sequential(Name("add1", add1), Name("add2", handWritten), add1, Name("noop", nil))
+	out, err := action.Run(ctx, 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Nil(t, out)
+	assert.Equal(t, []string{" sequential", "add1 action", "add2 action", " action", "noop noop"}, names)
+}
+
 func Test_Unit_Action_Wrap(t *testing.T) {
 	// arrange
 	action1 := Do(func(in int) (int, error) {
@@ -94,7 +152,7 @@ func Test_Unit_Action_Wrap(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// act
 			action := wrap(tc.action, tc.next)
-			out, err := action(tc.in)
+			out, err := action.Run(context.Background(), tc.in)
 
 			// assert
 			assert.Equal(t, tc.err, err)
@@ -161,7 +219,7 @@ func Test_Unit_Action_Sequential(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// act
 			action := Sequential(tc.actions...)
-			out, err := action(tc.in)
+			out, err := action.Run(context.Background(), tc.in)
 
 			// assert
 			assert.Equal(t, tc.err, err)
@@ -175,7 +233,7 @@ func Test_Unit_Action_Do_Success(t *testing.T) {
 	action := Do(func(in int) (int, error) {
 		return in + 1, nil
 	})
-	out, err := action(1)
+	out, err := action.Run(context.Background(), 1)
 
 	// assert
 	assert.NoError(t, err)
@@ -188,7 +246,7 @@ func Test_Unit_Action_Do_Error(t *testing.T) {
 	action := Do(func(in int) (int, error) {
 		return in + 1, actionErr
 	})
-	out, err := action(1)
+	out, err := action.Run(context.Background(), 1)
 
 	// assert
 	assert.Equal(t, actionErr, err)
@@ -310,7 +368,7 @@ func Test_Unit_Action_If(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// act
 			action := If(tc.condition, tc.ifTrue, tc.ifFalse)
-			out, err := action(tc.in)
+			out, err := action.Run(context.Background(), tc.in)
 
 			// assert
 			assert.Equal(t, tc.err, err)
@@ -385,7 +443,7 @@ func Test_Unit_Action_Parallel(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// act
 			action := Parallel(tc.reduce, tc.actions...)
-			out, err := action(1)
+			out, err := action.Run(context.Background(), 1)
 
 			// assert
 			assert.Equal(t, tc.err, err)
@@ -449,7 +507,7 @@ func Test_Unit_Action_Catch(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// act
 			action := Catch(tc.action, tc.handle)
-			out, err := action(tc.in)
+			out, err := action.Run(context.Background(), tc.in)
 
 			// assert w
 			assert.Equal(t, tc.err, err)
@@ -513,7 +571,7 @@ func Test_Unit_Action_Finally(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// act
 			action := Finally(tc.action, tc.finally)
-			out, err := action(tc.in)
+			out, err := action.Run(context.Background(), tc.in)
 
 			// assert w
 			assert.Equal(t, tc.err, err)
@@ -545,13 +603,13 @@ func Test_Unit_Action_AllActions(t *testing.T) {
 	}
 
 	actionWithNumErrs := func(numErrs int) Action {
-		return func(in any) (any, error) {
+		return ActionFunc(func(ctx context.Context, in any) (any, error) {
 			if numErrs <= 0 {
 				return in.(int) + 2, nil
 			}
 			numErrs--
 			return in.(int) + 1, errors.New("test error")
-		}
+		})
 	}
 
 	// act
@@ -583,7 +641,7 @@ func Test_Unit_Action_AllActions(t *testing.T) {
 			}), // 18 + 2 == 20
 		),
 	)
-	out, err := action(1)
+	out, err := action.Run(context.Background(), 1)
 
 	// assert
 	assert.NoError(t, err)
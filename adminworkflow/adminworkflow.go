@@ -0,0 +1,230 @@
+// Package adminworkflow serves a JSON API and a web page for starting, inspecting and cancelling the runs of a workflow engine.
+package adminworkflow
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	workflow "github.com/eleniums/go-workflow"
+)
+
+// Maximum size of the input of a run, in bytes.
+const maxInputSize = 1 << 20
+
+//go:embed index.html
+var indexHTML []byte
+
+// Serves the admin API and web page of an engine. Mount it with http.StripPrefix to serve it below a path, i.e. "/admin/".
+//
+// Endpoints:
+//   - GET /: web page that lists the runs and draws the step tree of a run.
+//   - GET /workflows: names of the registered workflows.
+//   - POST /workflows/{name}/runs: starts a run of the workflow with the JSON request body as input. Responds with the run.
+//   - GET /runs: runs, newest first. Filter with the workflow and status query parameters, where status can be repeated.
+//   - GET /runs/{id}: run, including the trace of its steps.
+//   - POST /runs/{id}/cancel: cancels the run. Responds with the run.
+//
+// Errors are returned as {"error": message} with status 400 for invalid input, 404 for unknown workflows and runs and 503 once the engine is shut down.
+type Handler struct {
+	engine *workflow.Engine
+	mux    *http.ServeMux
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// A registered workflow.
+type Workflow struct {
+	Name string `json:"name"`
+}
+
+// A run of a workflow.
+type Run struct {
+	ID           string          `json:"id"`
+	Workflow     string          `json:"workflow"`
+	Status       string          `json:"status"`
+	Started      *time.Time      `json:"started,omitempty"`
+	Ended        *time.Time      `json:"ended,omitempty"`
+	CurrentSteps []string        `json:"currentSteps,omitempty"`
+	Output       json.RawMessage `json:"output,omitempty"`
+	Error        string          `json:"error,omitempty"`
+
+	// Steps the run has executed, in the order they started. Only included when a single run is requested.
+	Trace []Step `json:"trace,omitempty"`
+}
+
+// A step executed by a run.
+type Step struct {
+	Path    string     `json:"path"`
+	Name    string     `json:"name,omitempty"`
+	Kind    string     `json:"kind"`
+	Attempt int        `json:"attempt,omitempty"`
+	Started time.Time  `json:"started"`
+	Ended   *time.Time `json:"ended,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// Creates a handler for the engine.
+func NewHandler(engine *workflow.Engine) *Handler {
+	h := &Handler{engine: engine, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /{$}", h.index)
+	h.mux.HandleFunc("GET /workflows", h.workflows)
+	h.mux.HandleFunc("POST /workflows/{name}/runs", h.start)
+	h.mux.HandleFunc("GET /runs", h.list)
+	h.mux.HandleFunc("GET /runs/{id}", h.get)
+	h.mux.HandleFunc("POST /runs/{id}/cancel", h.cancel)
+	return h
+}
+
+// Routes the request to an endpoint.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.mux.ServeHTTP(w, req)
+}
+
+func (h *Handler) index(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexHTML)
+}
+
+func (h *Handler) workflows(w http.ResponseWriter, req *http.Request) {
+	workflows := []Workflow{}
+	for _, v := range h.engine.Workflows() {
+		workflows = append(workflows, Workflow{Name: v})
+	}
+	writeJSON(w, http.StatusOK, workflows)
+}
+
+func (h *Handler) start(w http.ResponseWriter, req *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxInputSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var in any
+	if len(strings.TrimSpace(string(data))) > 0 {
+		if err := json.Unmarshal(data, &in); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	run, err := h.engine.Start(req.Context(), req.PathValue("name"), in)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, newRun(run, false))
+}
+
+func (h *Handler) list(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	filter := workflow.RunFilter{Workflow: query.Get("workflow")}
+	for _, v := range query["status"] {
+		filter.Status = append(filter.Status, workflow.RunStatus(v))
+	}
+
+	runs := []*Run{}
+	for _, v := range h.engine.List(filter) {
+		runs = append(runs, newRun(v, false))
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+func (h *Handler) get(w http.ResponseWriter, req *http.Request) {
+	run, err := h.engine.Get(req.PathValue("id"))
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newRun(run, true))
+}
+
+func (h *Handler) cancel(w http.ResponseWriter, req *http.Request) {
+	run, err := h.engine.Get(req.PathValue("id"))
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	run.Cancel()
+	writeJSON(w, http.StatusOK, newRun(run, false))
+}
+
+// Describes a run, with the trace of its steps if requested.
+func newRun(run *workflow.Run, trace bool) *Run {
+	status := run.Status()
+	r := &Run{
+		ID:           run.ID(),
+		Workflow:     run.Workflow(),
+		Status:       string(status),
+		CurrentSteps: run.CurrentSteps(),
+	}
+	started, ended := run.Times()
+	r.Started = timePtr(started)
+	r.Ended = timePtr(ended)
+
+	if status.Done() {
+		out, err := run.Wait()
+		if err != nil {
+			r.Error = err.Error()
+		}
+		if out != nil {
+			data, err := json.Marshal(out)
+			if err != nil && r.Error == "" {
+				r.Error = "output cannot be encoded as JSON: " + err.Error()
+			}
+			r.Output = data
+		}
+	}
+
+	if trace {
+		for _, v := range run.Trace() {
+			step := Step{
+				Path:    v.Path,
+				Name:    v.Name,
+				Kind:    string(v.Kind),
+				Attempt: v.Attempt,
+				Started: v.Started,
+				Ended:   timePtr(v.Ended),
+			}
+			if v.Err != nil {
+				step.Error = v.Err.Error()
+			}
+			r.Trace = append(r.Trace, step)
+		}
+	}
+	return r
+}
+
+// Returns nil for the zero time, so it is omitted from JSON.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// Returns the status code for an error returned by the engine.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, workflow.ErrWorkflowNotFound), errors.Is(err, workflow.ErrRunNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, workflow.ErrEngineShutdown):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
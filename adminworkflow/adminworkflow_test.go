@@ -0,0 +1,201 @@
+package adminworkflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+
+	workflow "github.com/eleniums/go-workflow"
+)
+
+// Creates an engine with a workflow that adds 1 to its input, one that fails and one that blocks until cancelled.
+func newTestServer(t *testing.T) (*workflow.Engine, *httptest.Server, chan struct{}) {
+	engine := workflow.NewEngine(nil)
+	engine.Register("add1", workflow.Name("root", workflow.Sequential(
+		workflow.Name("add", workflow.Do(func(in float64) (float64, error) {
+			return in + 1, nil
+		})),
+	)))
+	engine.Register("fail", workflow.ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return nil, errors.New("test error")
+	}))
+	started := make(chan struct{}, 1)
+	engine.Register("block", workflow.ActionFunc(func(ctx context.Context, in any) (any, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+
+	server := httptest.NewServer(NewHandler(engine))
+	t.Cleanup(server.Close)
+	return engine, server, started
+}
+
+// Sends a request and decodes the JSON response into out.
+func call(t *testing.T, method string, url string, body string, out any) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	if out != nil {
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp
+}
+
+func Test_Unit_AdminWorkflow_Workflows(t *testing.T) {
+	// arrange
+	_, server, _ := newTestServer(t)
+
+	// act
+	var workflows []Workflow
+	resp := call(t, http.MethodGet, server.URL+"/workflows", "", &workflows)
+
+	// assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, []Workflow{{Name: "add1"}, {Name: "block"}, {Name: "fail"}}, workflows)
+}
+
+func Test_Unit_AdminWorkflow_StartAndGet(t *testing.T) {
+	// arrange
+	engine, server, _ := newTestServer(t)
+
+	// act
+	var started Run
+	resp := call(t, http.MethodPost, server.URL+"/workflows/add1/runs", "1", &started)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	run, err := engine.Get(started.ID)
+	assert.NoError(t, err)
+	run.Wait()
+
+	var got Run
+	resp = call(t, http.MethodGet, server.URL+"/runs/"+started.ID, "", &got)
+
+	// assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "add1", got.Workflow)
+	assert.Equal(t, "succeeded", got.Status)
+	assert.JSONEq(t, "2", string(got.Output))
+	assert.Empty(t, got.Error)
+	assert.NotNil(t, got.Started)
+	assert.NotNil(t, got.Ended)
+	assert.Len(t, got.Trace, 2)
+	assert.Equal(t, "root", got.Trace[0].Path)
+	assert.Equal(t, "sequential", got.Trace[0].Kind)
+	assert.Equal(t, "root/add[0]", got.Trace[1].Path)
+	assert.Equal(t, "add", got.Trace[1].Name)
+	assert.NotNil(t, got.Trace[1].Ended)
+}
+
+func Test_Unit_AdminWorkflow_ListAndCancel(t *testing.T) {
+	// arrange
+	engine, server, started := newTestServer(t)
+	var failed, blocked Run
+	call(t, http.MethodPost, server.URL+"/workflows/fail/runs", "", &failed)
+	run, _ := engine.Get(failed.ID)
+	run.Wait()
+	call(t, http.MethodPost, server.URL+"/workflows/block/runs", `{"key": "value"}`, &blocked)
+	<-started
+
+	// act
+	var all, running, failures []Run
+	call(t, http.MethodGet, server.URL+"/runs", "", &all)
+	call(t, http.MethodGet, server.URL+"/runs?status=running&status=pending", "", &running)
+	call(t, http.MethodGet, server.URL+"/runs?workflow=fail&status=failed", "", &failures)
+
+	var cancelled Run
+	resp := call(t, http.MethodPost, server.URL+"/runs/"+blocked.ID+"/cancel", "", &cancelled)
+	run, _ = engine.Get(blocked.ID)
+	run.Wait()
+
+	// assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, all, 2)
+	assert.Equal(t, blocked.ID, all[0].ID)
+	assert.Equal(t, failed.ID, all[1].ID)
+	assert.Empty(t, all[0].Trace)
+	assert.Len(t, running, 1)
+	assert.Equal(t, blocked.ID, running[0].ID)
+	assert.Len(t, failures, 1)
+	assert.Equal(t, "test error", failures[0].Error)
+	assert.Equal(t, workflow.RunCancelled, run.Status())
+}
+
+func Test_Unit_AdminWorkflow_Errors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		err    string
+	}{
+		{name: "unknown workflow", method: http.MethodPost, path: "/workflows/missing/runs", status: http.StatusNotFound, err: `workflow not found: "missing"`},
+		{name: "invalid input", method: http.MethodPost, path: "/workflows/add1/runs", body: "{", status: http.StatusBadRequest, err: "unexpected end of JSON input"},
+		{name: "unknown run", method: http.MethodGet, path: "/runs/missing", status: http.StatusNotFound, err: `run not found: "missing"`},
+		{name: "cancel unknown run", method: http.MethodPost, path: "/runs/missing/cancel", status: http.StatusNotFound, err: `run not found: "missing"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			_, server, _ := newTestServer(t)
+
+			// act
+			var body map[string]string
+			resp := call(t, tc.method, server.URL+tc.path, tc.body, &body)
+
+			// assert
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.Equal(t, tc.err, body["error"])
+		})
+	}
+}
+
+func Test_Unit_AdminWorkflow_Shutdown(t *testing.T) {
+	// arrange
+	engine, server, _ := newTestServer(t)
+	assert.NoError(t, engine.Shutdown(context.Background()))
+
+	// act
+	var body map[string]string
+	resp := call(t, http.MethodPost, server.URL+"/workflows/add1/runs", "1", &body)
+
+	// assert
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "engine is shut down", body["error"])
+}
+
+func Test_Unit_AdminWorkflow_Index(t *testing.T) {
+	// arrange
+	engine := workflow.NewEngine(nil)
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", http.StripPrefix("/admin", NewHandler(engine)))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// act
+	resp, err := http.Get(server.URL + "/admin/")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), "<title>Workflows</title>")
+
+	var workflows []Workflow
+	call(t, http.MethodGet, server.URL+"/admin/workflows", "", &workflows)
+	assert.Empty(t, workflows)
+}
@@ -0,0 +1,119 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Groups items from concurrent callers into bulk calls, i.e. to use the bulk endpoint of an API from many workflows at once. Each caller passes one item and waits until a batch is full with maxSize items or maxWait has passed since the first item of the batch, whichever comes first. The bulk action is then executed once with a []any of the items in the batch.
+//
+// The bulk action must output a []Result or a slice with one element per item, in the same order as the items. Each caller receives the result for its own item. If the bulk action returns an error without a []Result, every caller in the batch receives the error.
+//
+// The bulk action is executed with the context of the first caller in the batch, without its cancellation. A caller whose context is cancelled stops waiting, but its item is still sent. Can set maxSize to 0 for no limit on the size of a batch.
+func Batch(bulkAction Action, maxSize int, maxWait time.Duration) Action {
+	b := &batcher{action: bulkAction, maxSize: maxSize, maxWait: maxWait}
+	return newStep(KindBatch, []Action{bulkAction}, b.submit)
+}
+
+// Collects items into batches.
+type batcher struct {
+	action  Action
+	maxSize int
+	maxWait time.Duration
+
+	lock    sync.Mutex
+	pending *batch
+}
+
+// Items waiting to be sent together.
+type batch struct {
+	ctx     context.Context
+	items   []any
+	results []Result
+	flushed sync.Once
+	done    chan struct{}
+}
+
+// Adds an item to the pending batch and waits for its result.
+func (b *batcher) submit(ctx context.Context, in any) (any, error) {
+	b.lock.Lock()
+	pending := b.pending
+	if pending == nil {
+		pending = &batch{ctx: context.WithoutCancel(ctx), done: make(chan struct{})}
+		b.pending = pending
+
+		timer := clockFromContext(ctx).NewTimer(b.maxWait)
+		go func() {
+			select {
+			case <-timer.C():
+				b.flush(pending)
+			case <-pending.done:
+				timer.Stop()
+			}
+		}()
+	}
+	index := len(pending.items)
+	pending.items = append(pending.items, in)
+	full := b.maxSize > 0 && len(pending.items) >= b.maxSize
+	if full {
+		// the next caller starts a new batch
+		b.pending = nil
+	}
+	b.lock.Unlock()
+
+	if full {
+		go b.flush(pending)
+	}
+
+	select {
+	case <-pending.done:
+		result := pending.results[index]
+		return result.Out, result.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Sends a batch with the bulk action and records the result for each item. Only the first call for a batch has any effect.
+func (b *batcher) flush(pending *batch) {
+	pending.flushed.Do(func() {
+		b.lock.Lock()
+		if b.pending == pending {
+			b.pending = nil
+		}
+		b.lock.Unlock()
+
+		// no more items can be added to the batch once it is no longer pending
+		out, err := runChild(pending.ctx, 0, b.action, pending.items)
+		pending.results = batchResults(out, err, len(pending.items))
+		close(pending.done)
+	})
+}
+
+// Splits the output of a bulk action into a result for each item.
+func batchResults(out any, err error, n int) []Result {
+	results := make([]Result, n)
+	if outputs, ok := out.([]Result); ok && len(outputs) == n {
+		copy(results, outputs)
+		return results
+	}
+
+	if err == nil {
+		v := reflect.ValueOf(out)
+		if v.Kind() == reflect.Slice && v.Len() == n {
+			for i := range results {
+				results[i].Out = v.Index(i).Interface()
+			}
+			return results
+		}
+		err = fmt.Errorf("batch: bulk action must output a slice with %d results, got %T", n, out)
+	}
+
+	for i := range results {
+		results[i].Err = err
+	}
+	return results
+}
@@ -0,0 +1,151 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+// Runs the action concurrently once for each input and returns the results in the same order.
+func runConcurrently(action Action, ins ...any) []Result {
+	results := make([]Result, len(ins))
+	var wg sync.WaitGroup
+	for i, v := range ins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := action.Run(context.Background(), v)
+			results[i] = Result{Out: out, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func Test_Unit_Batch_MaxSize(t *testing.T) {
+	// arrange
+	var lock sync.Mutex
+	var batches [][]any
+	bulk := Do(func(in []any) ([]string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		batches = append(batches, in)
+		out := []string{}
+		for _, v := range in {
+			out = append(out, fmt.Sprintf("item %d", v))
+		}
+		return out, nil
+	})
+	action := Batch(bulk, 2, time.Hour)
+
+	// act
+	results := runConcurrently(action, 1, 2, 3, 4)
+
+	// assert
+	assert.Len(t, batches, 2)
+	for i, v := range results {
+		assert.NoError(t, v.Err)
+		assert.Equal(t, fmt.Sprintf("item %d", i+1), v.Out)
+	}
+}
+
+func Test_Unit_Batch_MaxWait(t *testing.T) {
+	// arrange
+	calls := 0
+	bulk := Do(func(in []any) ([]any, error) {
+		calls++
+		return in, nil
+	})
+	action := Batch(bulk, 0, time.Millisecond*20)
+
+	// act
+	start := time.Now()
+	results := runConcurrently(action, "a", "b", "c")
+
+	// assert
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*20)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []Result{{Out: "a"}, {Out: "b"}, {Out: "c"}}, results)
+}
+
+func Test_Unit_Batch_Errors(t *testing.T) {
+	// arrange
+	itemErr := errors.New("item error")
+	bulkErr := errors.New("bulk error")
+
+	testCases := []struct {
+		name     string
+		bulk     Action
+		expected []Result
+	}{
+		{
+			name: "per item",
+			bulk: Do(func(in []any) ([]Result, error) {
+				results := []Result{}
+				for _, v := range in {
+					if v == "b" {
+						results = append(results, Result{Err: itemErr})
+					} else {
+						results = append(results, Result{Out: v})
+					}
+				}
+				return results, nil
+			}),
+			expected: []Result{{Out: "a"}, {Err: itemErr}},
+		},
+		{
+			name: "bulk error",
+			bulk: Do(func(in []any) ([]any, error) {
+				return nil, bulkErr
+			}),
+			expected: []Result{{Err: bulkErr}, {Err: bulkErr}},
+		},
+		{
+			name: "wrong length",
+			bulk: Do(func(in []any) ([]any, error) {
+				return []any{1}, nil
+			}),
+			expected: []Result{
+				{Err: errors.New("batch: bulk action must output a slice with 2 results, got []interface {}")},
+				{Err: errors.New("batch: bulk action must output a slice with 2 results, got []interface {}")},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			results := runConcurrently(Batch(tc.bulk, 2, time.Hour), "a", "b")
+
+			// assert
+			assert.Equal(t, tc.expected, results)
+		})
+	}
+}
+
+func Test_Unit_Batch_Cancel(t *testing.T) {
+	// arrange
+	action := Batch(Do(func(in []any) ([]any, error) {
+		return in, nil
+	}), 2, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// act
+	done := make(chan error)
+	go func() {
+		_, err := action.Run(ctx, 1)
+		done <- err
+	}()
+	cancel()
+	err := <-done
+	results := runConcurrently(action, 2)
+
+	// assert
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []Result{{Out: 2}}, results)
+}
@@ -0,0 +1,102 @@
+package workflow
+
+import (
+	"sync"
+	"time"
+)
+
+// Options for configuring a retry budget.
+type RetryBudgetOptions struct {
+	// Maximum number of retries as a fraction of requests in the window, i.e. 0.1 allows one retry for every ten requests. A request is the initial try of a retry step that uses the budget.
+	Ratio float64
+
+	// Number of retries allowed in the window regardless of the ratio, so retries still happen when there are few requests. Can set to 0 to only allow retries by ratio.
+	MinRetries int
+
+	// Length of the sliding window requests and retries are counted over. Can set to 0 for 10 seconds. Windows shorter than 10 nanoseconds are rounded up to 10 nanoseconds.
+	Window time.Duration
+
+	// Optional clock used to measure the window. Can set to nil to use the system clock.
+	Clock Clock
+}
+
+// Limits retries across every retry step that uses it, so a struggling dependency is not overwhelmed by retries when many workflows fail at once. Set RetryOptions.Budget to use it. Safe for concurrent use.
+type RetryBudget struct {
+	opts    RetryBudgetOptions
+	lock    sync.Mutex
+	buckets [budgetBuckets]budgetBucket
+}
+
+// Number of buckets the window is divided into. The window slides one bucket at a time.
+const budgetBuckets = 10
+
+// Requests and retries counted during one part of the window.
+type budgetBucket struct {
+	start    time.Time
+	requests int
+	retries  int
+}
+
+// Creates a retry budget. If opts is nil, retries may not exceed 10% of requests over 10 seconds, with at least 10 retries allowed.
+func NewRetryBudget(opts *RetryBudgetOptions) *RetryBudget {
+	if opts == nil {
+		opts = &RetryBudgetOptions{
+			Ratio:      0.1,
+			MinRetries: 10,
+		}
+	}
+
+	b := &RetryBudget{opts: *opts}
+	if b.opts.Window <= 0 {
+		b.opts.Window = time.Second * 10
+	}
+	if b.opts.Window < budgetBuckets {
+		// each bucket must be at least a nanosecond wide
+		b.opts.Window = budgetBuckets
+	}
+	if b.opts.Clock == nil {
+		b.opts.Clock = systemClock{}
+	}
+	return b
+}
+
+// Counts a request.
+func (b *RetryBudget) request() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.current().requests++
+}
+
+// Counts a retry and returns true if the budget allows it. Returns false without counting the retry if the budget is exhausted.
+func (b *RetryBudget) withdraw() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	current := b.current()
+	requests, retries := 0, 0
+	cutoff := b.opts.Clock.Now().Add(-b.opts.Window)
+	for _, v := range b.buckets {
+		if v.start.After(cutoff) {
+			requests += v.requests
+			retries += v.retries
+		}
+	}
+
+	if retries >= b.opts.MinRetries && float64(retries+1) > float64(requests)*b.opts.Ratio {
+		return false
+	}
+	current.retries++
+	return true
+}
+
+// Returns the bucket for the current time, clearing it if it was last used in a previous window. Must be called with the lock held.
+func (b *RetryBudget) current() *budgetBucket {
+	width := b.opts.Window / budgetBuckets
+	now := b.opts.Clock.Now()
+	start := now.Truncate(width)
+	bucket := &b.buckets[int(start.UnixNano()/int64(width))%budgetBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = budgetBucket{start: start}
+	}
+	return bucket
+}
@@ -0,0 +1,116 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func Test_Unit_RetryBudget_Ratio(t *testing.T) {
+	// arrange
+	clock := &advancingClock{now: time.Now()}
+	budget := NewRetryBudget(&RetryBudgetOptions{Ratio: 0.2, Window: time.Second * 10, Clock: clock})
+
+	// act
+	for i := 0; i < 10; i++ {
+		budget.request()
+	}
+	first := budget.withdraw()
+	second := budget.withdraw()
+	third := budget.withdraw()
+
+	// assert
+	assert.True(t, first)
+	assert.True(t, second)
+	assert.False(t, third)
+}
+
+func Test_Unit_RetryBudget_MinRetries(t *testing.T) {
+	// arrange
+	clock := &advancingClock{now: time.Now()}
+	budget := NewRetryBudget(&RetryBudgetOptions{Ratio: 0.1, MinRetries: 3, Clock: clock})
+
+	// act
+	var allowed []bool
+	for i := 0; i < 4; i++ {
+		allowed = append(allowed, budget.withdraw())
+	}
+
+	// assert
+	assert.Equal(t, []bool{true, true, true, false}, allowed)
+}
+
+func Test_Unit_RetryBudget_SlidingWindow(t *testing.T) {
+	// arrange
+	clock := &advancingClock{now: time.Now()}
+	budget := NewRetryBudget(&RetryBudgetOptions{Ratio: 0, MinRetries: 2, Window: time.Second * 10, Clock: clock})
+
+	// act
+	assert.True(t, budget.withdraw())
+	clock.Sleep(time.Second * 5)
+	assert.True(t, budget.withdraw())
+	denied := budget.withdraw()
+	clock.Sleep(time.Second * 6)
+	afterFirstExpired := budget.withdraw()
+	denied2 := budget.withdraw()
+	clock.Sleep(time.Second * 20)
+	afterAllExpired := budget.withdraw()
+
+	// assert
+	assert.False(t, denied)
+	assert.True(t, afterFirstExpired)
+	assert.False(t, denied2)
+	assert.True(t, afterAllExpired)
+}
+
+func Test_Unit_RetryBudget_ShortWindow(t *testing.T) {
+	// arrange
+	clock := &advancingClock{now: time.Now()}
+	budget := NewRetryBudget(&RetryBudgetOptions{Ratio: 0, MinRetries: 1, Window: time.Nanosecond * 5, Clock: clock})
+
+	// act
+	budget.request()
+	allowed := budget.withdraw()
+	denied := budget.withdraw()
+	clock.Sleep(time.Nanosecond * 20)
+	afterExpired := budget.withdraw()
+
+	// assert
+	assert.True(t, allowed)
+	assert.False(t, denied)
+	assert.True(t, afterExpired)
+}
+
+func Test_Unit_Action_Retry_Budget(t *testing.T) {
+	// arrange
+	actionErr := errors.New("test error")
+	calls := 0
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		calls++
+		return 5, actionErr
+	})
+	budget := NewRetryBudget(&RetryBudgetOptions{Ratio: 0, MinRetries: 3})
+	retry := Retry(action, &RetryOptions{MaxRetries: 2, Budget: budget})
+
+	var denied []int
+	ctx := WithHooks(context.Background(), &Hooks{
+		OnRetryDenied: func(ctx context.Context, step StepInfo, attempt int, err error) {
+			denied = append(denied, attempt)
+		},
+	})
+
+	// act
+	_, firstErr := retry.Run(ctx, nil)
+	out, secondErr := retry.Run(ctx, nil)
+
+	// assert
+	var retryErr *RetryError
+	assert.ErrorAs(t, firstErr, &retryErr)
+	assert.Equal(t, 5, out)
+	assert.Equal(t, actionErr, secondErr)
+	assert.Equal(t, 5, calls)
+	assert.Equal(t, []int{2}, denied)
+}
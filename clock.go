@@ -0,0 +1,87 @@
+package workflow
+
+import (
+	"context"
+	"time"
+)
+
+// Source of time for steps that wait, such as retries. Replace it with a fake clock to test workflows without waiting.
+type Clock interface {
+	// Returns the current time.
+	Now() time.Time
+
+	// Blocks until the duration has passed.
+	Sleep(d time.Duration)
+
+	// Returns a channel that receives the current time once the duration has passed.
+	After(d time.Duration) <-chan time.Time
+
+	// Returns a timer that sends the current time on its channel once the duration has passed.
+	NewTimer(d time.Duration) Timer
+}
+
+// A single event scheduled by a clock, like time.Timer.
+type Timer interface {
+	// Returns the channel the time is sent on when the timer fires.
+	C() <-chan time.Time
+
+	// Prevents the timer from firing. Returns false if the timer has already fired or been stopped.
+	Stop() bool
+
+	// Changes the timer to fire once the duration has passed. Returns true if the timer was active.
+	Reset(d time.Duration) bool
+}
+
+// Returns the clock that uses the time package.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t systemTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
+
+type clockKey struct{}
+
+// Returns a copy of the context that uses the clock for every step executed with it, unless the step has its own clock.
+func WithClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// Returns the clock attached to the context, or the system clock if there is none.
+func clockFromContext(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(clockKey{}).(Clock); ok && clock != nil {
+		return clock
+	}
+	return systemClock{}
+}
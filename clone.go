@@ -0,0 +1,204 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// Implemented by values that know how to copy themselves, such as types with unexported fields that DeepCopy cannot copy.
+type Cloner interface {
+	// Returns a copy of the value that shares nothing the original can modify.
+	Clone() any
+}
+
+// Returned by a parallel step when mutation detection is enabled and an action modified the input the actions share.
+var ErrInputMutated = errors.New("parallel: input was modified by an action")
+
+// Returns a deep copy of the value. Values that implement Cloner are copied with Clone. Pointers, maps, slices, arrays, interfaces and the exported fields of structs are copied recursively. Unexported fields, functions and channels are shared with the original.
+func DeepCopy(in any) any {
+	if in == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(in), map[copyKey]reflect.Value{}).Interface()
+}
+
+type mutationKey struct{}
+
+// Returns a copy of the context that makes every parallel step check whether its actions modified the input they share. The input is copied with DeepCopy before the actions execute and compared once they complete, returning ErrInputMutated if it changed. Meant for tests and debugging, since copying every input is expensive.
+func WithMutationDetection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, mutationKey{}, true)
+}
+
+// Returns true if mutation detection is enabled for the context.
+func detectMutation(ctx context.Context) bool {
+	enabled, _ := ctx.Value(mutationKey{}).(bool)
+	return enabled
+}
+
+var clonerType = reflect.TypeFor[Cloner]()
+
+// Identifies a copied pointer. The type is part of the key because a pointer to a struct and a pointer to its first field have the same address.
+type copyKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// Copies a value recursively. Pointers that were already copied are reused, so cycles and shared pointers are preserved.
+func deepCopy(v reflect.Value, copied map[copyKey]reflect.Value) reflect.Value {
+	if v.Type().Implements(clonerType) && v.CanInterface() && !isNil(v) {
+		if clone := reflect.ValueOf(v.Interface().(Cloner).Clone()); clone.IsValid() && clone.Type().AssignableTo(v.Type()) {
+			return clone
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		key := copyKey{ptr: v.Pointer(), typ: v.Type()}
+		if existing, ok := copied[key]; ok {
+			return existing
+		}
+		out := reflect.New(v.Type().Elem())
+		copied[key] = out
+		out.Elem().Set(deepCopy(v.Elem(), copied))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(deepCopy(v.Elem(), copied))
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(deepCopy(iter.Key(), copied), deepCopy(iter.Value(), copied))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(deepCopy(v.Index(i), copied))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			out.Index(i).Set(deepCopy(v.Index(i), copied))
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(deepCopy(v.Field(i), copied))
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// Returns true if the value is a nil pointer, interface, map, slice, function or channel.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// Compares two values recursively like reflect.DeepEqual, except that functions and channels are equal when they are the same value, so a copy made by DeepCopy is always equal to the original.
+func deepEqual(a reflect.Value, b reflect.Value, visited map[[2]uintptr]bool) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.Pointer() == b.Pointer() {
+			return true
+		}
+		if a.IsNil() || b.IsNil() {
+			return false
+		}
+		key := [2]uintptr{a.Pointer(), b.Pointer()}
+		if visited[key] {
+			return true
+		}
+		visited[key] = true
+		return deepEqual(a.Elem(), b.Elem(), visited)
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return deepEqual(a.Elem(), b.Elem(), visited)
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() || a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			value := b.MapIndex(iter.Key())
+			if !value.IsValid() || !deepEqual(iter.Value(), value, visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.IsNil() != b.IsNil() {
+			return false
+		}
+		fallthrough
+	case reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := range a.Len() {
+			if !deepEqual(a.Index(i), b.Index(i), visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if !deepEqual(a.Field(i), b.Field(i), visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer()
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		// NaN is not equal to itself, but a copy of NaN has not been modified
+		return a.Float() == b.Float() || (a.Float() != a.Float() && b.Float() != b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	default:
+		return true
+	}
+}
@@ -0,0 +1,171 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+type testDocument struct {
+	Title  string
+	Tags   []string
+	Fields map[string]any
+	Parent *testDocument
+	Notify func()
+	hidden *int
+}
+
+type testCloner struct {
+	values []int
+	clones int
+}
+
+func (c *testCloner) Clone() any {
+	c.clones++
+	return &testCloner{values: append([]int{}, c.values...)}
+}
+
+func Test_Unit_DeepCopy(t *testing.T) {
+	// arrange
+	hidden := 1
+	parent := &testDocument{Title: "parent"}
+	in := &testDocument{
+		Title:  "child",
+		Tags:   []string{"a", "b"},
+		Fields: map[string]any{"nested": []any{1, map[string]any{"key": "value"}}},
+		Parent: parent,
+		Notify: func() {},
+		hidden: &hidden,
+	}
+	parent.Parent = parent
+
+	// act
+	out := DeepCopy(in).(*testDocument)
+
+	// assert
+	assert.True(t, deepEqual(reflect.ValueOf(in), reflect.ValueOf(out), map[[2]uintptr]bool{}))
+	assert.NotSame(t, in, out)
+	assert.NotSame(t, in.Parent, out.Parent)
+	assert.Same(t, out.Parent, out.Parent.Parent)
+	assert.Same(t, in.hidden, out.hidden)
+
+	out.Tags[0] = "changed"
+	out.Fields["nested"].([]any)[1].(map[string]any)["key"] = "changed"
+	out.Parent.Title = "changed"
+	assert.Equal(t, "a", in.Tags[0])
+	assert.Equal(t, "value", in.Fields["nested"].([]any)[1].(map[string]any)["key"])
+	assert.Equal(t, "parent", in.Parent.Title)
+	assert.False(t, deepEqual(reflect.ValueOf(in), reflect.ValueOf(out), map[[2]uintptr]bool{}))
+}
+
+func Test_Unit_DeepCopy_FieldPointer(t *testing.T) {
+	// arrange
+	type inner struct {
+		A int
+	}
+	type outer struct {
+		P *inner
+		Q *int
+	}
+	s := &inner{A: 1}
+	in := outer{P: s, Q: &s.A}
+
+	// act
+	out := DeepCopy(in).(outer)
+
+	// assert
+	assert.NotSame(t, s, out.P)
+	assert.NotSame(t, &s.A, out.Q)
+	assert.Equal(t, 1, out.P.A)
+	assert.Equal(t, 1, *out.Q)
+}
+
+func Test_Unit_DeepCopy_Cloner(t *testing.T) {
+	// arrange
+	cloner := &testCloner{values: []int{1, 2}}
+	in := map[string]*testCloner{"key": cloner}
+
+	// act
+	out := DeepCopy(in).(map[string]*testCloner)
+
+	// assert
+	assert.Equal(t, 1, cloner.clones)
+	assert.NotSame(t, cloner, out["key"])
+	assert.Equal(t, []int{1, 2}, out["key"].values)
+	assert.Nil(t, DeepCopy(nil))
+	assert.Equal(t, 5, DeepCopy(5))
+}
+
+func Test_Unit_ParallelWithOptions_Clone(t *testing.T) {
+	// arrange
+	in := map[string]int{"count": 0}
+	var lock sync.Mutex
+	var outputs []map[string]int
+	increment := func(key string) Action {
+		return Do(func(in map[string]int) (map[string]int, error) {
+			in[key]++
+			lock.Lock()
+			defer lock.Unlock()
+			outputs = append(outputs, in)
+			return in, nil
+		})
+	}
+	action := ParallelWithOptions(&ParallelOptions{Clone: DeepCopy}, func(in []Result) (int, error) {
+		return len(in), nil
+	}, increment("a"), increment("b"), increment("count"))
+
+	// act
+	out, err := action.Run(context.Background(), in)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, out)
+	assert.Equal(t, map[string]int{"count": 0}, in)
+	assert.ElementsMatch(t, []map[string]int{
+		{"count": 0, "a": 1},
+		{"count": 0, "b": 1},
+		{"count": 1},
+	}, outputs)
+}
+
+func Test_Unit_Parallel_MutationDetection(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   *ParallelOptions
+		mutate bool
+		err    error
+	}{
+		{name: "mutated", opts: nil, mutate: true, err: ErrInputMutated},
+		{name: "not mutated", opts: nil, mutate: false, err: nil},
+		{name: "cloned", opts: &ParallelOptions{Clone: DeepCopy}, mutate: true, err: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			in := &testDocument{Title: "title", Tags: []string{"a"}, Notify: func() {}}
+			action := ParallelWithOptions(tc.opts, func(in []Result) (int, error) {
+				return 0, errors.Join(in[0].Err, in[1].Err)
+			}, NoOp(), Do(func(in *testDocument) (*testDocument, error) {
+				if tc.mutate {
+					in.Tags[0] = "b"
+				}
+				return in, nil
+			}))
+
+			// act
+			_, err := action.Run(WithMutationDetection(context.Background()), in)
+
+			// assert
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+// Command workflow validates, draws and runs declarative workflow definitions.
+//
+// Usage:
+//
+//	workflow validate <file>
+//	workflow graph [-format dot|mermaid] <file>
+//	workflow run [-input file] [-trace=false] <file>
+//
+// The run command reads JSON input from stdin unless -input is given, writes the JSON output to stdout and writes the step trace to stderr.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	workflow "github.com/eleniums/go-workflow"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+const usage = `usage:
+  workflow validate <file>
+  workflow graph [-format dot|mermaid] <file>
+  workflow run [-input file] [-trace=false] <file>
+`
+
+// Executes a command and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "validate":
+		err = validate(args[1:], stdout)
+	case "graph":
+		err = graph(args[1:], stdout)
+	case "run":
+		err = execute(ctx, args[1:], stdin, stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintf(stderr, "%v\n%s", err, usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// Error caused by invalid command line arguments.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// Parses flags and returns the workflow file argument.
+func parse(flags *flag.FlagSet, args []string) (string, error) {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return "", &usageError{msg: err.Error()}
+	}
+	if flags.NArg() != 1 {
+		return "", &usageError{msg: "expected a single workflow file"}
+	}
+	return flags.Arg(0), nil
+}
+
+// Loads a workflow definition file with the built-in steps.
+func load(path string) (workflow.Action, error) {
+	return newRegistry().LoadFile(path)
+}
+
+func validate(args []string, stdout io.Writer) error {
+	path, err := parse(flag.NewFlagSet("validate", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	if _, err := load(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s is valid\n", path)
+	return nil
+}
+
+func graph(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("graph", flag.ContinueOnError)
+	format := flags.String("format", "dot", "graph format: dot or mermaid")
+	path, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+
+	action, err := load(path)
+	if err != nil {
+		return err
+	}
+
+	node := workflow.Describe(action)
+	switch *format {
+	case "dot":
+		fmt.Fprint(stdout, node.DOT())
+	case "mermaid":
+		fmt.Fprint(stdout, node.Mermaid())
+	default:
+		return &usageError{msg: fmt.Sprintf("unknown graph format %q", *format)}
+	}
+	return nil
+}
+
+func execute(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	inputPath := flags.String("input", "", "file to read JSON input from instead of stdin")
+	showTrace := flags.Bool("trace", true, "write the step trace to stderr")
+	path, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+
+	action, err := load(path)
+	if err != nil {
+		return err
+	}
+
+	input := stdin
+	if *inputPath != "" {
+		file, err := os.Open(*inputPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var in any
+	if err := json.NewDecoder(input).Decode(&in); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid JSON input: %w", err)
+	}
+
+	t := &trace{}
+	out, runErr := action.Run(workflow.WithHooks(ctx, t.hooks()), in)
+	if *showTrace {
+		t.write(stderr)
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return err
+	}
+	return runErr
+}
+
+// Records each step as it executes.
+type trace struct {
+	lock    sync.Mutex
+	entries []*traceEntry
+}
+
+// A single step in a trace.
+type traceEntry struct {
+	step    workflow.StepInfo
+	depth   int
+	elapsed time.Duration
+	err     error
+}
+
+type depthKey struct{}
+
+type entryKey struct{}
+
+type startKey struct{}
+
+// Returns hooks that record every step to the trace.
+func (t *trace) hooks() *workflow.Hooks {
+	return &workflow.Hooks{
+		OnStepStart: func(ctx context.Context, step workflow.StepInfo, in any) context.Context {
+			depth, _ := ctx.Value(depthKey{}).(int)
+			entry := &traceEntry{step: step, depth: depth, elapsed: -1}
+
+			t.lock.Lock()
+			t.entries = append(t.entries, entry)
+			t.lock.Unlock()
+
+			ctx = context.WithValue(ctx, depthKey{}, depth+1)
+			ctx = context.WithValue(ctx, entryKey{}, entry)
+			return context.WithValue(ctx, startKey{}, time.Now())
+		},
+		OnStepEnd: func(ctx context.Context, step workflow.StepInfo, out any, err error) {
+			entry := ctx.Value(entryKey{}).(*traceEntry)
+			start := ctx.Value(startKey{}).(time.Time)
+
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			entry.elapsed = time.Since(start)
+			entry.err = err
+		},
+	}
+}
+
+// Writes the trace with each step indented under the step that contains it.
+func (t *trace) write(w io.Writer) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, v := range t.entries {
+		label := string(v.step.Kind)
+		if v.step.Name != "" {
+			label = v.step.Name + " (" + label + ")"
+		}
+		if v.step.Attempt > 0 {
+			label += fmt.Sprintf(" attempt %d", v.step.Attempt)
+		}
+
+		status := "ok"
+		if v.err != nil {
+			status = "failed: " + v.err.Error()
+		}
+		fmt.Fprintf(w, "%s%s %s %s\n", strings.Repeat("  ", v.depth), label, status, v.elapsed.Round(time.Microsecond))
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name string, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func runCommand(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func Test_Unit_Main_Validate(t *testing.T) {
+	// arrange
+	valid := writeFile(t, "valid.yaml", "shell: {command: cat}\n")
+	invalid := writeFile(t, "invalid.yaml", "sequential:\n  - shell: {}\n  - action: missing\n")
+
+	// act
+	code, stdout, _ := runCommand("", "validate", valid)
+
+	// assert
+	assert.Equal(t, 0, code)
+	assert.Equal(t, valid+" is valid\n", stdout)
+
+	// act
+	code, _, stderr := runCommand("", "validate", invalid)
+
+	// assert
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "line 2, column 12: invalid shell step: command is required")
+	assert.Contains(t, stderr, `line 3, column 13: unknown action "missing"`)
+}
+
+func Test_Unit_Main_Graph(t *testing.T) {
+	// arrange
+	path := writeFile(t, "workflow.yaml", "sequential:\n  - readFile: {path: in.txt}\n  - writeFile: {path: out.txt}\n")
+
+	// act
+	code, dot, _ := runCommand("", "graph", path)
+
+	// assert
+	assert.Equal(t, 0, code)
+	assert.Contains(t, dot, "digraph workflow {")
+	assert.Contains(t, dot, `n1 [label="readFile (action)", shape=box];`)
+
+	// act
+	code, mermaid, _ := runCommand("", "graph", "-format", "mermaid", path)
+
+	// assert
+	assert.Equal(t, 0, code)
+	assert.Contains(t, mermaid, "flowchart TD")
+	assert.Contains(t, mermaid, `n2["writeFile (action)"]`)
+
+	// act
+	code, _, stderr := runCommand("", "graph", "-format", "png", path)
+
+	// assert
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown graph format "png"`)
+}
+
+func Test_Unit_Main_Run(t *testing.T) {
+	// arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(`{"method": "` + r.Method + `", "token": "` + r.Header.Get("X-Token") + `", "body": ` + string(body) + `}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.json")
+	path := writeFile(t, "workflow.yaml", `
+sequential:
+  - shell: {command: "cat", json: true}
+  - http:
+      url: `+server.URL+`
+      method: post
+      headers: {X-Token: secret}
+      json: true
+  - writeFile: {path: `+out+`}
+  - parallel:
+      reduce: collect
+      actions:
+        - readFile: {path: `+out+`, json: true}
+        - if:
+            condition: truthy
+            then: {readFile: {path: `+out+`, json: true}}
+            else: {noop: {}}
+`)
+
+	// act
+	code, stdout, stderr := runCommand(`{"value": 1}`, "run", path)
+
+	// assert
+	assert.Equal(t, 0, code, stderr)
+	assert.JSONEq(t, `[
+		{"method": "POST", "token": "secret", "body": {"value": 1}},
+		{"method": "POST", "token": "secret", "body": {"value": 1}}
+	]`, stdout)
+
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	assert.Len(t, lines, 9)
+	assert.True(t, strings.HasPrefix(lines[0], "sequential ok "), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "  shell (action) ok "), lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], "    exec (action) ok "), lines[2])
+	assert.True(t, strings.HasPrefix(lines[3], "  http (action) ok "), lines[3])
+	assert.True(t, strings.HasPrefix(lines[4], "  writeFile (action) ok "), lines[4])
+	assert.True(t, strings.HasPrefix(lines[5], "  parallel ok "), lines[5])
+}
+
+func Test_Unit_Main_RunInputFileAndError(t *testing.T) {
+	// arrange
+	input := writeFile(t, "input.json", `"hello"`)
+	path := writeFile(t, "workflow.yaml", `
+sequential:
+  - shell: {command: "tr a-z A-Z"}
+  - shell: {command: "cat; echo broken >&2; exit 3"}
+`)
+
+	// act
+	code, stdout, stderr := runCommand("", "run", "-input", input, "-trace=false", path)
+
+	// assert
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "\"HELLO\"\n", stdout)
+	assert.Equal(t, "cat; echo broken >&2; exit 3: exit status 3: broken\n", stderr)
+}
+
+func Test_Unit_Main_Usage(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "no command", args: nil, err: "usage:"},
+		{name: "unknown command", args: []string{"draw"}, err: `unknown command "draw"`},
+		{name: "missing file", args: []string{"run"}, err: "expected a single workflow file"},
+		{name: "unknown flag", args: []string{"validate", "-x", "file"}, err: "flag provided but not defined: -x"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			code, _, stderr := runCommand("", tc.args...)
+
+			// assert
+			assert.Equal(t, 2, code)
+			assert.Contains(t, stderr, tc.err)
+		})
+	}
+}
+
+func Test_Unit_Main_InvalidInput(t *testing.T) {
+	// arrange
+	path := writeFile(t, "workflow.yaml", "noop: {}\n")
+
+	// act
+	code, _, stderr := runCommand("{", "run", path)
+
+	// assert
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "invalid JSON input")
+}
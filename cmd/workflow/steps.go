@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	workflow "github.com/eleniums/go-workflow"
+)
+
+// Returns a registry with the built-in steps, conditions, reducers and handlers.
+//
+// Steps:
+//   - shell: {command, json}: runs the command with "sh -c", writing the input to stdin. Outputs stdout.
+//   - readFile: {path, json}: outputs the contents of the file.
+//   - writeFile: {path}: writes the input to the file. Outputs the input unchanged.
+//   - http: {url, method, headers, json}: sends the input as the request body unless the method is GET. Outputs the response body.
+//
+// When json is true, the output is decoded as JSON instead of returned as a string. Inputs that are not strings are encoded as JSON before they are written.
+//
+// Conditions: "truthy" is true for any input other than null, false, 0 and "".
+// Reducers: "collect" outputs the result of each parallel action as a list, or the first error.
+// Handlers: "ignore" discards the error and keeps the output.
+func newRegistry() *workflow.Registry {
+	registry := workflow.NewRegistry()
+	registry.RegisterStep("shell", shellStep)
+	registry.RegisterStep("readFile", readFileStep)
+	registry.RegisterStep("writeFile", writeFileStep)
+	registry.RegisterStep("http", httpStep)
+	registry.RegisterCondition("truthy", truthy)
+	registry.RegisterReducer("collect", collect)
+	registry.RegisterHandler("ignore", func(out any, err error) (any, error) {
+		return out, nil
+	})
+	return registry
+}
+
+func shellStep(params map[string]any) (workflow.Action, error) {
+	var p struct {
+		Command string
+		JSON    bool
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Command == "" {
+		return nil, errors.New("command is required")
+	}
+
+	shell := workflow.Name("exec", workflow.Exec(workflow.ExecSpec{Name: "sh", Args: []string{"-c", p.Command}}))
+	return workflow.ActionFunc(func(ctx context.Context, in any) (any, error) {
+		stdin, err := encode(in)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := shell.Run(ctx, stdin)
+		result, _ := out.(*workflow.ExecResult)
+		if result == nil {
+			return nil, fmt.Errorf("%s: %w", p.Command, err)
+		}
+		if err != nil {
+			return result.Stdout, fmt.Errorf("%s: %w", p.Command, err)
+		}
+		return decode([]byte(result.Stdout), p.JSON)
+	}), nil
+}
+
+func readFileStep(params map[string]any) (workflow.Action, error) {
+	var p struct {
+		Path string
+		JSON bool
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Path == "" {
+		return nil, errors.New("path is required")
+	}
+
+	return workflow.ActionFunc(func(ctx context.Context, in any) (any, error) {
+		data, err := os.ReadFile(p.Path)
+		if err != nil {
+			return nil, err
+		}
+		return decode(data, p.JSON)
+	}), nil
+}
+
+func writeFileStep(params map[string]any) (workflow.Action, error) {
+	var p struct {
+		Path string
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Path == "" {
+		return nil, errors.New("path is required")
+	}
+
+	return workflow.ActionFunc(func(ctx context.Context, in any) (any, error) {
+		data, err := encode(in)
+		if err != nil {
+			return nil, err
+		}
+		return in, os.WriteFile(p.Path, data, 0o644)
+	}), nil
+}
+
+func httpStep(params map[string]any) (workflow.Action, error) {
+	var p struct {
+		URL     string
+		Method  string
+		Headers map[string]string
+		JSON    bool
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.URL == "" {
+		return nil, errors.New("url is required")
+	}
+	if p.Method == "" {
+		p.Method = http.MethodGet
+	}
+	p.Method = strings.ToUpper(p.Method)
+
+	return workflow.ActionFunc(func(ctx context.Context, in any) (any, error) {
+		var body io.Reader
+		if p.Method != http.MethodGet {
+			data, err := encode(in)
+			if err != nil {
+				return nil, err
+			}
+			body = bytes.NewReader(data)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, p.Method, p.URL, body)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range p.Headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return string(data), fmt.Errorf("%s %s: %s", p.Method, p.URL, resp.Status)
+		}
+		return decode(data, p.JSON)
+	}), nil
+}
+
+// True for any value other than null, false, 0 and "".
+func truthy(in any) (bool, error) {
+	switch v := in.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case float64:
+		return v != 0, nil
+	case string:
+		return v != "", nil
+	default:
+		return true, nil
+	}
+}
+
+// Returns the output of each action, or the first error.
+func collect(in []workflow.Result) (any, error) {
+	outputs := []any{}
+	for _, v := range in {
+		if v.Err != nil {
+			return nil, v.Err
+		}
+		outputs = append(outputs, v.Out)
+	}
+	return outputs, nil
+}
+
+// Decodes step fields into a struct, matching field names case-insensitively.
+func decodeParams(params map[string]any, target any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(target)
+}
+
+// Returns strings as-is and encodes anything else as JSON.
+func encode(in any) ([]byte, error) {
+	switch v := in.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// Decodes data as JSON if requested, otherwise returns it as a string.
+func decode(data []byte, isJSON bool) (any, error) {
+	if !isJSON {
+		return string(data), nil
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return string(data), err
+	}
+	return out, nil
+}
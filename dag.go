@@ -0,0 +1,204 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Options for configuring a DAG.
+type DAGOptions struct {
+	// Maximum number of nodes that can execute at the same time. Can set to 0 for no limit.
+	MaxConcurrency int
+}
+
+// Builds a directed acyclic graph of actions, where each node executes as soon as the nodes it depends on have completed.
+type DAG struct {
+	opts  DAGOptions
+	nodes []*dagNode
+}
+
+// A named action in a DAG and the nodes it depends on.
+type dagNode struct {
+	name   string
+	action Action
+	needs  []string
+}
+
+// Creates an empty DAG. If opts is nil, there is no concurrency limit.
+func NewDAG(opts *DAGOptions) *DAG {
+	d := &DAG{}
+	if opts != nil {
+		d.opts = *opts
+	}
+	return d
+}
+
+// Adds a node that executes the action once every node it needs has completed. A node that needs no other nodes receives the input of the DAG. Any other node receives a map[string]any of the outputs of the nodes it needs, by name. The action is given the name of the node. Returns the DAG so calls can be chained.
+func (d *DAG) Add(name string, action Action, needs ...string) *DAG {
+	d.nodes = append(d.nodes, &dagNode{name: name, action: Name(name, action), needs: needs})
+	return d
+}
+
+// Validates the graph and returns an action that executes it. The action outputs a map[string]any of the output of every node, by name. If a node fails, no more nodes are started, the context of any nodes that are executing is cancelled and the outputs of the nodes that completed are returned with the error.
+//
+// Every error found is returned, including duplicate names, missing dependencies and cycles.
+func (d *DAG) Build() (Action, error) {
+	index := map[string]int{}
+	var errs []error
+	for i, v := range d.nodes {
+		if _, ok := index[v.name]; ok {
+			errs = append(errs, fmt.Errorf("duplicate node %q", v.name))
+			continue
+		}
+		index[v.name] = i
+	}
+	for _, v := range d.nodes {
+		for _, need := range v.needs {
+			if _, ok := index[need]; !ok {
+				errs = append(errs, fmt.Errorf("node %q needs unknown node %q", v.name, need))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if cycle := d.cycle(index); cycle != nil {
+		return nil, errors.Join(fmt.Errorf("cycle between nodes: %s", strings.Join(cycle, " -> ")))
+	}
+
+	nodes := append([]*dagNode{}, d.nodes...)
+	children := make([]Action, len(nodes))
+	needs := make([][]string, len(nodes))
+	for i, v := range nodes {
+		children[i] = v.action
+		needs[i] = v.needs
+	}
+
+	s := newStep(KindDAG, children, func(ctx context.Context, in any) (any, error) {
+		return runDAG(ctx, nodes, index, d.opts.MaxConcurrency, in)
+	})
+	s.needs = needs
+	return s, nil
+}
+
+// Returns the names of the nodes in a cycle, starting and ending with the same node, or nil if there is no cycle.
+func (d *DAG) cycle(index map[string]int) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(d.nodes))
+	var path []string
+
+	var visit func(i int) []string
+	visit = func(i int) []string {
+		state[i] = visiting
+		path = append(path, d.nodes[i].name)
+		for _, need := range d.nodes[i].needs {
+			j := index[need]
+			switch state[j] {
+			case visiting:
+				start := 0
+				for path[start] != need {
+					start++
+				}
+				return append(append([]string{}, path[start:]...), need)
+			case unvisited:
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+
+	for i := range d.nodes {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// Result of a node in a DAG.
+type dagResult struct {
+	node int
+	out  any
+	err  error
+}
+
+// Executes the nodes of a DAG, starting each node once the nodes it needs have completed.
+func runDAG(ctx context.Context, nodes []*dagNode, index map[string]int, maxConcurrency int, in any) (any, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// count the nodes each node is waiting for and which nodes are waiting for it
+	waiting := make([]int, len(nodes))
+	dependents := make([][]int, len(nodes))
+	var ready []int
+	for i, v := range nodes {
+		waiting[i] = len(v.needs)
+		for _, need := range v.needs {
+			dependents[index[need]] = append(dependents[index[need]], i)
+		}
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	outputs := map[string]any{}
+	done := make(chan dagResult)
+	running := 0
+	var err error
+	for {
+		// start every node that is ready, up to the concurrency limit
+		for err == nil && len(ready) > 0 && (maxConcurrency <= 0 || running < maxConcurrency) {
+			i := ready[0]
+			ready = ready[1:]
+
+			input := in
+			if len(nodes[i].needs) > 0 {
+				needed := map[string]any{}
+				for _, need := range nodes[i].needs {
+					needed[need] = outputs[need]
+				}
+				input = needed
+			}
+
+			running++
+			go func(i int, in any) {
+				out, err := runChild(ctx, i, nodes[i].action, in)
+				done <- dagResult{node: i, out: out, err: err}
+			}(i, input)
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-done
+		running--
+		if result.err != nil {
+			if err == nil {
+				err = fmt.Errorf("%s: %w", nodes[result.node].name, result.err)
+				cancel()
+			}
+			continue
+		}
+
+		outputs[nodes[result.node].name] = result.out
+		for _, v := range dependents[result.node] {
+			waiting[v]--
+			if waiting[v] == 0 {
+				ready = append(ready, v)
+			}
+		}
+	}
+	return outputs, err
+}
@@ -0,0 +1,216 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func Test_Unit_DAG_Outputs(t *testing.T) {
+	// arrange
+	sum := func(in map[string]any) (int, error) {
+		total := 0
+		for _, v := range in {
+			total += v.(int)
+		}
+		return total, nil
+	}
+	action, err := NewDAG(nil).
+		Add("d", Do(sum), "c", "a").
+		Add("a", Do(func(in int) (int, error) {
+			return in + 1, nil
+		})).
+		Add("b", Do(func(in int) (int, error) {
+			return in * 10, nil
+		})).
+		Add("c", Do(sum), "a", "b").
+		Build()
+	assert.NoError(t, err)
+
+	// act
+	out, err := action.Run(context.Background(), 2)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"a": 3,
+		"b": 20,
+		"c": 23,
+		"d": 26,
+	}, out)
+}
+
+func Test_Unit_DAG_RunsWhenReady(t *testing.T) {
+	// arrange
+	var lock sync.Mutex
+	var completed []string
+	node := func(name string, delay time.Duration) Action {
+		return ActionFunc(func(ctx context.Context, in any) (any, error) {
+			time.Sleep(delay)
+			lock.Lock()
+			defer lock.Unlock()
+			completed = append(completed, name)
+			return name, nil
+		})
+	}
+	action, err := NewDAG(nil).
+		Add("slow", node("slow", time.Millisecond*100)).
+		Add("fast", node("fast", 0)).
+		Add("afterFast", node("afterFast", 0), "fast").
+		Add("afterBoth", node("afterBoth", 0), "slow", "afterFast").
+		Build()
+	assert.NoError(t, err)
+
+	// act
+	_, err = action.Run(context.Background(), nil)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fast", "afterFast", "slow", "afterBoth"}, completed)
+}
+
+func Test_Unit_DAG_MaxConcurrency(t *testing.T) {
+	// arrange
+	var running, maxRunning atomic.Int32
+	node := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			max := maxRunning.Load()
+			if n <= max || maxRunning.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 10)
+		return nil, nil
+	})
+	dag := NewDAG(&DAGOptions{MaxConcurrency: 2})
+	for _, v := range []string{"a", "b", "c", "d", "e"} {
+		dag.Add(v, node)
+	}
+	action, err := dag.Build()
+	assert.NoError(t, err)
+
+	// act
+	out, err := action.Run(context.Background(), nil)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, out, 5)
+	assert.Equal(t, int32(2), maxRunning.Load())
+}
+
+func Test_Unit_DAG_Error(t *testing.T) {
+	// arrange
+	nodeErr := errors.New("test error")
+	cancelled := make(chan error, 1)
+	action, err := NewDAG(nil).
+		Add("ok", Do(func(in int) (int, error) {
+			return in, nil
+		})).
+		Add("fail", Do(func(in map[string]any) (int, error) {
+			return 0, nodeErr
+		}), "ok").
+		Add("wait", ActionFunc(func(ctx context.Context, in any) (any, error) {
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return nil, ctx.Err()
+		})).
+		Add("never", NoOp(), "fail").
+		Build()
+	assert.NoError(t, err)
+
+	// act
+	out, err := action.Run(context.Background(), 1)
+
+	// assert
+	assert.ErrorIs(t, err, nodeErr)
+	assert.Equal(t, "fail: test error", err.Error())
+	assert.Equal(t, map[string]any{"ok": 1}, out)
+	assert.Equal(t, context.Canceled, <-cancelled)
+}
+
+func Test_Unit_DAG_Invalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		dag  *DAG
+		errs []string
+	}{
+		{
+			name: "cycle",
+			dag: NewDAG(nil).
+				Add("a", NoOp()).
+				Add("b", NoOp(), "a", "d").
+				Add("c", NoOp(), "b").
+				Add("d", NoOp(), "c"),
+			errs: []string{"cycle between nodes: b -> d -> c -> b"},
+		},
+		{
+			name: "self",
+			dag:  NewDAG(nil).Add("a", NoOp(), "a"),
+			errs: []string{"cycle between nodes: a -> a"},
+		},
+		{
+			name: "duplicate and unknown",
+			dag: NewDAG(nil).
+				Add("a", NoOp()).
+				Add("a", NoOp(), "missing"),
+			errs: []string{
+				`duplicate node "a"`,
+				`node "a" needs unknown node "missing"`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			action, err := tc.dag.Build()
+
+			// assert
+			assert.Nil(t, action)
+			var errs []string
+			for _, v := range err.(interface{ Unwrap() []error }).Unwrap() {
+				errs = append(errs, v.Error())
+			}
+			assert.Equal(t, tc.errs, errs)
+		})
+	}
+}
+
+func Test_Unit_DAG_Graph(t *testing.T) {
+	// arrange
+	action, err := NewDAG(nil).
+		Add("a", NoOp()).
+		Add("b", NoOp()).
+		Add("c", NoOp(), "a", "b").
+		Build()
+	assert.NoError(t, err)
+
+	// act
+	node := Describe(action)
+	dot := node.DOT()
+
+	// assert
+	assert.Equal(t, &Node{Kind: KindDAG, Children: []*Node{
+		{Name: "a", Kind: KindNoOp},
+		{Name: "b", Kind: KindNoOp},
+		{Name: "c", Kind: KindNoOp, Needs: []string{"a", "b"}},
+	}}, node)
+	assert.Equal(t, `digraph workflow {
+	n0 [label="dag", shape=box];
+	n1 [label="a (noop)", shape=box];
+	n2 [label="b (noop)", shape=box];
+	n3 [label="c (noop)", shape=box];
+	n0 -> n1;
+	n0 -> n2;
+	n1 -> n3;
+	n2 -> n3;
+}
+`, dot)
+}
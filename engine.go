@@ -0,0 +1,249 @@
+package workflow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// Returned when starting a workflow that was not registered with the engine.
+	ErrWorkflowNotFound = errors.New("workflow not found")
+
+	// Returned when a run ID is not known to the engine, either because it never existed or because it was removed from the history.
+	ErrRunNotFound = errors.New("run not found")
+
+	// Returned when starting a workflow after the engine has been shut down.
+	ErrEngineShutdown = errors.New("engine is shut down")
+)
+
+// Options for configuring an engine.
+type EngineOptions struct {
+	// Maximum number of runs that can execute at the same time. Runs started beyond the limit are pending until another run completes. Can set to 0 for no limit.
+	MaxConcurrency int
+
+	// Maximum number of completed runs kept in the history. The oldest runs are removed first. Can set to 0 to keep 1000.
+	MaxHistory int
+
+	// Completed runs are removed from the history once they have been completed for this long. Can set to 0 to only limit the history with MaxHistory.
+	Retention time.Duration
+
+	// Optional clock used to measure retention, which is also given to every run. Can set to nil to use the system clock.
+	Clock Clock
+}
+
+// Executes registered workflows in the background and keeps track of their runs, so they can be listed, inspected and cancelled by ID. Safe for concurrent use.
+type Engine struct {
+	opts  EngineOptions
+	slots chan struct{}
+
+	lock      sync.Mutex
+	workflows map[string]Action
+	runs      []*Run
+	byID      map[string]*Run
+	shutdown  bool
+}
+
+// Selects runs to list. Any field can be left empty to match every run.
+type RunFilter struct {
+	// Name of the workflow the runs were started from.
+	Workflow string
+
+	// Statuses the runs must have one of.
+	Status []RunStatus
+}
+
+// Creates an engine with no workflows. If opts is nil, there is no concurrency limit and the last 1000 completed runs are kept.
+func NewEngine(opts *EngineOptions) *Engine {
+	e := &Engine{
+		workflows: map[string]Action{},
+		byID:      map[string]*Run{},
+	}
+	if opts != nil {
+		e.opts = *opts
+	}
+	if e.opts.MaxHistory <= 0 {
+		e.opts.MaxHistory = 1000
+	}
+	if e.opts.MaxConcurrency > 0 {
+		e.slots = make(chan struct{}, e.opts.MaxConcurrency)
+	}
+	return e
+}
+
+// Registers an action so it can be started by name. Registering a name again replaces the action for runs started afterwards.
+func (e *Engine) Register(name string, action Action) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.workflows[name] = action
+}
+
+// Returns the names of the registered workflows, sorted.
+func (e *Engine) Workflows() []string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	names := make([]string, 0, len(e.workflows))
+	for name := range e.workflows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Starts a run of a registered workflow in the background and returns it. The run keeps the values of ctx, such as hooks, but is not cancelled with it, so a run can outlive the request that started it. Use Cancel or Shutdown to cancel it.
+func (e *Engine) Start(ctx context.Context, workflow string, in any) (*Run, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.shutdown {
+		return nil, ErrEngineShutdown
+	}
+	action, ok := e.workflows[workflow]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrWorkflowNotFound, workflow)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if e.opts.Clock != nil {
+		ctx = WithClock(ctx, e.opts.Clock)
+	}
+	r := newRun(ctx)
+	r.id = newRunID()
+	r.workflow = workflow
+	e.runs = append(e.runs, r)
+	e.byID[r.id] = r
+	e.prune()
+
+	go func() {
+		if e.slots != nil {
+			select {
+			case e.slots <- struct{}{}:
+				defer func() { <-e.slots }()
+			case <-r.done:
+				// cancelled while pending
+				return
+			}
+		}
+		r.execute(action, in)
+	}()
+	return r, nil
+}
+
+// Returns the run with the ID, or ErrRunNotFound.
+func (e *Engine) Get(id string) (*Run, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.prune()
+	r, ok := e.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrRunNotFound, id)
+	}
+	return r, nil
+}
+
+// Returns the runs that match the filter, newest first. Completed runs are included until they are removed from the history.
+func (e *Engine) List(filter RunFilter) []*Run {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.prune()
+	var runs []*Run
+	for i := len(e.runs) - 1; i >= 0; i-- {
+		r := e.runs[i]
+		if filter.Workflow != "" && r.workflow != filter.Workflow {
+			continue
+		}
+		if len(filter.Status) > 0 && !slices.Contains(filter.Status, r.Status()) {
+			continue
+		}
+		runs = append(runs, r)
+	}
+	return runs
+}
+
+// Cancels the run with the ID, or returns ErrRunNotFound. Cancelling a run that has completed has no effect.
+func (e *Engine) Cancel(id string) error {
+	r, err := e.Get(id)
+	if err != nil {
+		return err
+	}
+	r.Cancel()
+	return nil
+}
+
+// Stops accepting new runs and waits for the runs that are pending or running to complete. If ctx is done first, the remaining runs are cancelled and the error of ctx is returned without waiting for them to return.
+func (e *Engine) Shutdown(ctx context.Context) error {
+	e.lock.Lock()
+	e.shutdown = true
+	var active []*Run
+	for _, v := range e.runs {
+		if !v.Status().Done() {
+			active = append(active, v)
+		}
+	}
+	e.lock.Unlock()
+
+	for _, v := range active {
+		select {
+		case <-v.Done():
+		case <-ctx.Done():
+			for _, v := range active {
+				v.Cancel()
+			}
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Removes completed runs that are past the retention or beyond the maximum history. The lock must be held.
+func (e *Engine) prune() {
+	now := time.Time{}
+	if e.opts.Retention > 0 {
+		now = e.clock().Now()
+	}
+
+	// the newest completed runs are kept
+	keep := make([]bool, len(e.runs))
+	completed := 0
+	for i := len(e.runs) - 1; i >= 0; i-- {
+		r := e.runs[i]
+		keep[i] = true
+		if r.Status().Done() {
+			_, ended := r.Times()
+			completed++
+			if completed > e.opts.MaxHistory || (e.opts.Retention > 0 && now.Sub(ended) >= e.opts.Retention) {
+				keep[i] = false
+				delete(e.byID, r.id)
+			}
+		}
+	}
+
+	kept := e.runs[:0]
+	for i, v := range e.runs {
+		if keep[i] {
+			kept = append(kept, v)
+		}
+	}
+	clear(e.runs[len(kept):])
+	e.runs = kept
+}
+
+// Returns the clock used to measure retention.
+func (e *Engine) clock() Clock {
+	if e.opts.Clock != nil {
+		return e.opts.Clock
+	}
+	return systemClock{}
+}
+
+// Returns a random ID for a run.
+func newRunID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
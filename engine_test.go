@@ -0,0 +1,239 @@
+package workflow
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+type manualClock struct {
+	systemClock
+	lock sync.Mutex
+	now  time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Returns an action that blocks until release is closed or its context is cancelled.
+func blockingAction(started chan<- string, release <-chan struct{}) Action {
+	return DoContext(func(ctx context.Context, in string) (string, error) {
+		if started != nil {
+			started <- in
+		}
+		select {
+		case <-release:
+			return in, nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	})
+}
+
+func Test_Unit_Engine_StartAndGet(t *testing.T) {
+	// arrange
+	engine := NewEngine(nil)
+	engine.Register("upper", Do(func(in string) (string, error) {
+		return in + "!", nil
+	}))
+
+	// act
+	run, err := engine.Start(context.Background(), "upper", "hello")
+	assert.NoError(t, err)
+	out, err := run.Wait()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "hello!", out)
+	assert.Len(t, run.ID(), 16)
+	assert.Equal(t, "upper", run.Workflow())
+
+	found, err := engine.Get(run.ID())
+	assert.NoError(t, err)
+	assert.Same(t, run, found)
+	assert.Equal(t, []string{"upper"}, engine.Workflows())
+}
+
+func Test_Unit_Engine_Errors(t *testing.T) {
+	// arrange
+	engine := NewEngine(nil)
+
+	// act
+	_, startErr := engine.Start(context.Background(), "missing", nil)
+	_, getErr := engine.Get("missing")
+	cancelErr := engine.Cancel("missing")
+	shutdownErr := engine.Shutdown(context.Background())
+	_, closedErr := engine.Start(context.Background(), "missing", nil)
+
+	// assert
+	assert.ErrorIs(t, startErr, ErrWorkflowNotFound)
+	assert.EqualError(t, startErr, `workflow not found: "missing"`)
+	assert.ErrorIs(t, getErr, ErrRunNotFound)
+	assert.ErrorIs(t, cancelErr, ErrRunNotFound)
+	assert.NoError(t, shutdownErr)
+	assert.ErrorIs(t, closedErr, ErrEngineShutdown)
+}
+
+func Test_Unit_Engine_List(t *testing.T) {
+	// arrange
+	engine := NewEngine(nil)
+	release := make(chan struct{})
+	started := make(chan string, 1)
+	engine.Register("blocking", blockingAction(started, release))
+	engine.Register("echo", Do(func(in string) (string, error) {
+		return in, nil
+	}))
+
+	echo1, _ := engine.Start(context.Background(), "echo", "1")
+	echo1.Wait()
+	blocking, _ := engine.Start(context.Background(), "blocking", "2")
+	<-started
+	echo2, _ := engine.Start(context.Background(), "echo", "3")
+	echo2.Wait()
+
+	// act
+	all := engine.List(RunFilter{})
+	echoes := engine.List(RunFilter{Workflow: "echo"})
+	running := engine.List(RunFilter{Status: []RunStatus{RunRunning, RunPending}})
+	none := engine.List(RunFilter{Workflow: "blocking", Status: []RunStatus{RunSucceeded}})
+	close(release)
+	blocking.Wait()
+
+	// assert
+	assert.Equal(t, []*Run{echo2, blocking, echo1}, all)
+	assert.Equal(t, []*Run{echo2, echo1}, echoes)
+	assert.Equal(t, []*Run{blocking}, running)
+	assert.Empty(t, none)
+}
+
+func Test_Unit_Engine_Cancel(t *testing.T) {
+	// arrange
+	engine := NewEngine(nil)
+	started := make(chan string, 1)
+	engine.Register("blocking", blockingAction(started, nil))
+	run, _ := engine.Start(context.Background(), "blocking", "in")
+	<-started
+
+	// act
+	err := engine.Cancel(run.ID())
+	<-run.Done()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, RunCancelled, run.Status())
+}
+
+func Test_Unit_Engine_MaxConcurrency(t *testing.T) {
+	// arrange
+	engine := NewEngine(&EngineOptions{MaxConcurrency: 1})
+	release := make(chan struct{})
+	started := make(chan string, 3)
+	engine.Register("blocking", blockingAction(started, release))
+
+	// act
+	first, _ := engine.Start(context.Background(), "blocking", "first")
+	<-started
+	second, _ := engine.Start(context.Background(), "blocking", "second")
+	third, _ := engine.Start(context.Background(), "blocking", "third")
+	third.Cancel()
+	secondStatus := second.Status()
+	close(release)
+	first.Wait()
+	second.Wait()
+
+	// assert
+	assert.Equal(t, RunPending, secondStatus)
+	assert.Equal(t, RunSucceeded, first.Status())
+	assert.Equal(t, RunSucceeded, second.Status())
+	assert.Equal(t, RunCancelled, third.Status())
+	assert.Equal(t, "second", <-started)
+	assert.Empty(t, started)
+}
+
+func Test_Unit_Engine_History(t *testing.T) {
+	// arrange
+	clock := &manualClock{now: time.Now()}
+	engine := NewEngine(&EngineOptions{MaxHistory: 2, Retention: time.Minute, Clock: clock})
+	release := make(chan struct{})
+	started := make(chan string, 1)
+	engine.Register("blocking", blockingAction(started, release))
+	engine.Register("echo", Do(func(in string) (string, error) {
+		return in, nil
+	}))
+
+	blocking, _ := engine.Start(context.Background(), "blocking", "0")
+	<-started
+	var echoes []*Run
+	for _, v := range []string{"1", "2", "3"} {
+		run, _ := engine.Start(context.Background(), "echo", v)
+		run.Wait()
+		echoes = append(echoes, run)
+		clock.Advance(time.Second * 20)
+	}
+
+	// act
+	byCount := engine.List(RunFilter{})
+	clock.Advance(time.Second * 30)
+	byRetention := engine.List(RunFilter{})
+	_, err := engine.Get(echoes[0].ID())
+	close(release)
+	blocking.Wait()
+
+	// assert
+	assert.Equal(t, []*Run{echoes[2], echoes[1], blocking}, byCount)
+	assert.Equal(t, []*Run{echoes[2], blocking}, byRetention)
+	assert.ErrorIs(t, err, ErrRunNotFound)
+}
+
+func Test_Unit_Engine_Shutdown(t *testing.T) {
+	testCases := []struct {
+		name    string
+		release bool
+		err     error
+		status  RunStatus
+	}{
+		{name: "drained", release: true, err: nil, status: RunSucceeded},
+		{name: "timeout", release: false, err: context.DeadlineExceeded, status: RunCancelled},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			engine := NewEngine(nil)
+			release := make(chan struct{})
+			started := make(chan string, 1)
+			engine.Register("blocking", blockingAction(started, release))
+			run, _ := engine.Start(context.Background(), "blocking", "in")
+			<-started
+			if tc.release {
+				close(release)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+			defer cancel()
+
+			// act
+			err := engine.Shutdown(ctx)
+			run.Wait()
+
+			// assert
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.status, run.Status())
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Describes an external process to run.
+type ExecSpec struct {
+	// Name or path of the program to run.
+	Name string
+
+	// Arguments passed to the program.
+	Args []string
+
+	// Environment variables in the form "key=value", added to the environment of the current process.
+	Env []string
+
+	// Working directory of the process. Can set to empty to use the working directory of the current process.
+	Dir string
+}
+
+// Input that adds to the spec of an exec action for a single run.
+type ExecInput struct {
+	// Arguments appended to the arguments in the spec.
+	Args []string
+
+	// Environment variables appended to the environment in the spec.
+	Env []string
+
+	// Working directory that replaces the directory in the spec. Can set to empty to keep the spec directory.
+	Dir string
+
+	// Written to the standard input of the process.
+	Stdin []byte
+}
+
+// Output of an exec action.
+type ExecResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Returned by an exec action when the process exits with a non-zero exit code.
+type ExitError struct {
+	// Output of the process.
+	Result *ExecResult
+
+	// Error returned when waiting for the process.
+	Err error
+}
+
+// Formats the error with the standard error of the process, if any.
+func (e *ExitError) Error() string {
+	stderr := strings.TrimSpace(e.Result.Stderr)
+	if stderr == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Err, stderr)
+}
+
+// Returns the error returned when waiting for the process.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Returns an action that runs an external process and outputs an *ExecResult. The input can be an ExecInput (or a pointer to one) to add arguments, environment variables, a working directory and standard input, a string or []byte to use as standard input, or nil. A non-zero exit code returns the result along with an *ExitError. If the context is cancelled, the process and any processes it started are killed.
+func Exec(spec ExecSpec) Action {
+	return newStep(KindAction, nil, func(ctx context.Context, in any) (any, error) {
+		args := append([]string{}, spec.Args...)
+		env := append([]string{}, spec.Env...)
+		dir := spec.Dir
+		var stdin []byte
+
+		switch v := in.(type) {
+		case nil:
+		case ExecInput:
+			args, env, dir, stdin = append(args, v.Args...), append(env, v.Env...), execDir(dir, v.Dir), v.Stdin
+		case *ExecInput:
+			args, env, dir, stdin = append(args, v.Args...), append(env, v.Env...), execDir(dir, v.Dir), v.Stdin
+		case string:
+			stdin = []byte(v)
+		case []byte:
+			stdin = v
+		default:
+			return nil, fmt.Errorf("exec %s: unsupported input type %T", spec.Name, in)
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, spec.Name, args...)
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Dir = dir
+		cmd.Stdin = bytes.NewReader(stdin)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		killProcessGroup(cmd)
+
+		err := cmd.Run()
+		result := &ExecResult{
+			Stdout:   stdout.String(),
+			Stderr:   stderr.String(),
+			ExitCode: cmd.ProcessState.ExitCode(),
+		}
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return result, &ExitError{Result: result, Err: err}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	})
+}
+
+// Returns the override directory if set, otherwise the spec directory.
+func execDir(dir string, override string) string {
+	if override != "" {
+		return override
+	}
+	return dir
+}
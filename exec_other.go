@@ -0,0 +1,11 @@
+//go:build !unix
+
+package workflow
+
+import (
+	"os/exec"
+)
+
+// Process groups are only supported on unix, so only the process itself is killed when the context is cancelled.
+func killProcessGroup(cmd *exec.Cmd) {
+}
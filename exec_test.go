@@ -0,0 +1,175 @@
+//go:build unix
+
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Exec(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name     string
+		spec     ExecSpec
+		in       any
+		expected *ExecResult
+	}{
+		{
+			name:     "no input",
+			spec:     ExecSpec{Name: "echo", Args: []string{"hello"}},
+			in:       nil,
+			expected: &ExecResult{Stdout: "hello\n"},
+		},
+		{
+			name:     "string stdin",
+			spec:     ExecSpec{Name: "cat"},
+			in:       "from string",
+			expected: &ExecResult{Stdout: "from string"},
+		},
+		{
+			name:     "bytes stdin",
+			spec:     ExecSpec{Name: "cat"},
+			in:       []byte("from bytes"),
+			expected: &ExecResult{Stdout: "from bytes"},
+		},
+		{
+			name: "exec input",
+			spec: ExecSpec{Name: "sh", Args: []string{"-c"}, Env: []string{"A=1"}, Dir: "/"},
+			in: ExecInput{
+				Args:  []string{`echo "$A $B $(pwd) $(cat)"; echo oops >&2`},
+				Env:   []string{"B=2"},
+				Dir:   dir,
+				Stdin: []byte("stdin"),
+			},
+			expected: &ExecResult{Stdout: "1 2 " + dir + " stdin\n", Stderr: "oops\n"},
+		},
+		{
+			name:     "exec input pointer",
+			spec:     ExecSpec{Name: "sh", Args: []string{"-c", "pwd"}, Dir: dir},
+			in:       &ExecInput{},
+			expected: &ExecResult{Stdout: dir + "\n"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			out, err := Exec(tc.spec).Run(context.Background(), tc.in)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}
+
+func Test_Unit_Exec_Errors(t *testing.T) {
+	// act
+	out, err := Exec(ExecSpec{Name: "sh", Args: []string{"-c", "echo partial; echo failed >&2; exit 3"}}).Run(context.Background(), nil)
+
+	// assert
+	var exitErr *ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, "exit status 3: failed", err.Error())
+	assert.Equal(t, &ExecResult{Stdout: "partial\n", Stderr: "failed\n", ExitCode: 3}, out)
+	assert.Equal(t, out, exitErr.Result)
+
+	var cmdErr *exec.ExitError
+	assert.ErrorAs(t, err, &cmdErr)
+
+	// act
+	_, err = Exec(ExecSpec{Name: "does-not-exist"}).Run(context.Background(), nil)
+
+	// assert
+	assert.ErrorIs(t, err, exec.ErrNotFound)
+	assert.False(t, errors.As(err, &exitErr))
+
+	// act
+	_, err = Exec(ExecSpec{Name: "cat"}).Run(context.Background(), 1)
+
+	// assert
+	assert.EqualError(t, err, "exec cat: unsupported input type int")
+}
+
+func Test_Unit_Exec_RetryAndCatch(t *testing.T) {
+	// arrange
+	counter := filepath.Join(t.TempDir(), "counter")
+	flaky := Exec(ExecSpec{Name: "sh", Args: []string{"-c", `echo x >> "$0"; [ $(wc -l < "$0") -ge 3 ]`, counter}})
+
+	// act
+	out, err := Retry(flaky, &RetryOptions{
+		MaxRetries: 3,
+		ShouldRetry: func(out any, err error) bool {
+			var exitErr *ExitError
+			return errors.As(err, &exitErr)
+		},
+	}).Run(context.Background(), nil)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, out.(*ExecResult).ExitCode)
+
+	// act
+	out, err = Catch(Exec(ExecSpec{Name: "false"}), func(out any, err error) (any, error) {
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.Result.ExitCode, nil
+		}
+		return nil, err
+	}).Run(context.Background(), nil)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, out)
+}
+
+func Test_Unit_Exec_CancelKillsProcessGroup(t *testing.T) {
+	// arrange
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	action := Exec(ExecSpec{Name: "sh", Args: []string{"-c", `sleep 30 & echo $! > "$0.tmp"; mv "$0.tmp" "$0"; wait`, pidFile}})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		for {
+			if _, err := os.Stat(pidFile); err == nil {
+				cancel()
+				return
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	}()
+
+	// act
+	start := time.Now()
+	_, err := action.Run(ctx, nil)
+
+	// assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second*10)
+
+	data, err := os.ReadFile(pidFile)
+	assert.NoError(t, err)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		// killed processes may linger as zombies until they are reaped
+		stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+		if err == nil {
+			return strings.Contains(string(stat), ") Z ")
+		}
+		return syscall.Kill(pid, 0) != nil
+	}, time.Second*5, time.Millisecond*10)
+}
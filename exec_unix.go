@@ -0,0 +1,16 @@
+//go:build unix
+
+package workflow
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// Starts the process in its own process group and kills the entire group when the context is cancelled.
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,165 @@
+package workflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Describes a step in a workflow and the steps it contains.
+type Node struct {
+	// Name given to the step with Name. Empty if the step was not named.
+	Name string
+
+	// Kind of the step, i.e. sequential or parallel.
+	Kind Kind
+
+	// Steps contained by this step, in the order they were passed. For an if step, the first child is executed when the condition is true and the second when it is false.
+	Children []*Node
+
+	// Options used by a retry step. Nil for any other kind of step.
+	Retry *RetryOptions
+
+	// Names of the steps this step needs when it is a node in a DAG. Each is the name of another child of the DAG.
+	Needs []string
+}
+
+// Returns the graph of steps that make up an action. Actions that were not created by this package, such as an ActionFunc, are described as a step of kind action.
+func Describe(action Action) *Node {
+	s, ok := action.(*step)
+	if !ok {
+		return &Node{Kind: KindAction}
+	}
+
+	node := &Node{
+		Name:  s.name,
+		Kind:  s.kind,
+		Retry: s.retry,
+	}
+	for i, v := range s.children {
+		if v == nil {
+			continue
+		}
+		child := Describe(v)
+		if s.needs != nil {
+			child.Needs = s.needs[i]
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node
+}
+
+// Renders the graph as a Graphviz DOT digraph.
+func (n *Node) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph workflow {\n")
+	n.walk(func(id string, node *Node) {
+		shape := "box"
+		if node.Kind == KindIf {
+			shape = "diamond"
+		}
+		fmt.Fprintf(&b, "\t%s [label=%s, shape=%s];\n", id, strconv.Quote(node.label("\n")), shape)
+	}, func(from string, to string, label string) {
+		if label == "" {
+			fmt.Fprintf(&b, "\t%s -> %s;\n", from, to)
+		} else {
+			fmt.Fprintf(&b, "\t%s -> %s [label=%s];\n", from, to, strconv.Quote(label))
+		}
+	})
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Renders the graph as a Mermaid flowchart.
+func (n *Node) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	n.walk(func(id string, node *Node) {
+		label := strings.ReplaceAll(node.label("<br/>"), `"`, "#quot;")
+		if node.Kind == KindIf {
+			fmt.Fprintf(&b, "    %s{\"%s\"}\n", id, label)
+		} else {
+			fmt.Fprintf(&b, "    %s[\"%s\"]\n", id, label)
+		}
+	}, func(from string, to string, label string) {
+		if label == "" {
+			fmt.Fprintf(&b, "    %s --> %s\n", from, to)
+		} else {
+			fmt.Fprintf(&b, "    %s -->|%s| %s\n", from, label, to)
+		}
+	})
+	return b.String()
+}
+
+// Visits every node in depth-first order, assigning each an id, followed by the edges to its children. The children of a DAG are connected to the steps they need instead, and only those that need no other steps are connected to the DAG.
+func (n *Node) walk(visitNode func(id string, node *Node), visitEdge func(from string, to string, label string)) {
+	next := 0
+	var visit func(node *Node) string
+	visit = func(node *Node) string {
+		id := "n" + strconv.Itoa(next)
+		next++
+		visitNode(id, node)
+
+		if node.Kind != KindDAG {
+			for i, v := range node.Children {
+				child := visit(v)
+				visitEdge(id, child, node.edgeLabel(i))
+			}
+			return id
+		}
+
+		// a node can need a node that comes after it, so visit every node before the edges
+		ids := map[string]string{}
+		children := make([]string, len(node.Children))
+		for i, v := range node.Children {
+			children[i] = visit(v)
+			ids[v.Name] = children[i]
+		}
+		for i, v := range node.Children {
+			if len(v.Needs) == 0 {
+				visitEdge(id, children[i], "")
+			}
+			for _, need := range v.Needs {
+				visitEdge(ids[need], children[i], "")
+			}
+		}
+		return id
+	}
+	visit(n)
+}
+
+// Describes the node for display, with each line separated by sep.
+func (n *Node) label(sep string) string {
+	label := string(n.Kind)
+	if n.Name != "" {
+		label = n.Name + " (" + label + ")"
+	}
+	if n.Retry != nil {
+		label += sep + fmt.Sprintf("max retries: %d", n.Retry.MaxRetries)
+		if n.Retry.InitialDelay > 0 {
+			label += sep + fmt.Sprintf("initial delay: %s", n.Retry.InitialDelay)
+		}
+		if n.Retry.MaxDelay > 0 {
+			label += sep + fmt.Sprintf("max delay: %s", n.Retry.MaxDelay)
+		}
+		if n.Retry.MaxElapsedTime > 0 {
+			label += sep + fmt.Sprintf("max elapsed time: %s", n.Retry.MaxElapsedTime)
+		}
+	}
+	return label
+}
+
+// Returns the label for the edge to the child at index i.
+func (n *Node) edgeLabel(i int) string {
+	switch n.Kind {
+	case KindSequential, KindPipeline:
+		return strconv.Itoa(i + 1)
+	case KindIf:
+		if i == 0 {
+			return "true"
+		}
+		return "false"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,121 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Graph_Describe(t *testing.T) {
+	// arrange
+	add1 := func(in int) (int, error) {
+		return in + 1, nil
+	}
+	isOdd := func(in int) (bool, error) {
+		return in%2 == 1, nil
+	}
+	sum := func(in []Result) (int, error) {
+		return 0, nil
+	}
+	opts := &RetryOptions{MaxRetries: 2}
+	handWritten := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return in, nil
+	})
+
+	action := Sequential(
+		Name("first", Do(add1)),
+		Parallel(sum, Do(add1), handWritten),
+		If(isOdd, NoOp(), Retry(Do(add1), opts)),
+		nil,
+	)
+
+	// act
+	node := Describe(action)
+
+	// assert
+	assert.Equal(t, &Node{
+		Kind: KindSequential,
+		Children: []*Node{
+			{Name: "first", Kind: KindAction},
+			{Kind: KindParallel, Children: []*Node{
+				{Kind: KindAction},
+				{Kind: KindAction},
+			}},
+			{Kind: KindIf, Children: []*Node{
+				{Kind: KindNoOp},
+				{Kind: KindRetry, Retry: opts, Children: []*Node{
+					{Kind: KindAction},
+				}},
+			}},
+		},
+	}, node)
+}
+
+func Test_Unit_Graph_DefaultRetryOptions(t *testing.T) {
+	// act
+	node := Describe(Retry(NoOp(), nil))
+
+	// assert
+	assert.Equal(t, 3, node.Retry.MaxRetries)
+	assert.Equal(t, time.Millisecond*200, node.Retry.InitialDelay)
+}
+
+func Test_Unit_Graph_DOT(t *testing.T) {
+	// arrange
+	node := Describe(Sequential(
+		Name(`say "hi"`, NoOp()),
+		If(func(in int) (bool, error) {
+			return true, nil
+		}, NoOp(), Retry(NoOp(), &RetryOptions{MaxRetries: 2, InitialDelay: time.Second})),
+	))
+
+	// act
+	dot := node.DOT()
+
+	// assert
+	assert.Equal(t, `digraph workflow {
+	n0 [label="sequential", shape=box];
+	n1 [label="say \"hi\" (noop)", shape=box];
+	n0 -> n1 [label="1"];
+	n2 [label="if", shape=diamond];
+	n3 [label="noop", shape=box];
+	n2 -> n3 [label="true"];
+	n4 [label="retry\nmax retries: 2\ninitial delay: 1s", shape=box];
+	n5 [label="noop", shape=box];
+	n4 -> n5;
+	n2 -> n4 [label="false"];
+	n0 -> n2 [label="2"];
+}
+`, dot)
+}
+
+func Test_Unit_Graph_Mermaid(t *testing.T) {
+	// arrange
+	sum := func(in []Result) (int, error) {
+		return 0, nil
+	}
+	node := Describe(Parallel(sum,
+		Name(`say "hi"`, NoOp()),
+		If(func(in int) (bool, error) {
+			return true, nil
+		}, NoOp(), NoOp()),
+	))
+
+	// act
+	mermaid := node.Mermaid()
+
+	// assert
+	assert.Equal(t, `flowchart TD
+    n0["parallel"]
+    n1["say #quot;hi#quot; (noop)"]
+    n0 --> n1
+    n2{"if"}
+    n3["noop"]
+    n2 -->|true| n3
+    n4["noop"]
+    n2 -->|false| n4
+    n0 --> n2
+`, mermaid)
+}
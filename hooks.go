@@ -0,0 +1,135 @@
+package workflow
+
+import (
+	"context"
+	"time"
+)
+
+// Optional callbacks that are called as a workflow executes. Any function can be set to nil.
+type Hooks struct {
+	// Called before a step executes. The returned context is passed to the step and any steps it contains, which allows values such as a span to follow the combinator tree. Can return the provided context unchanged.
+	OnStepStart func(ctx context.Context, step StepInfo, in any) context.Context
+
+	// Called after a step completes, with the context returned by OnStepStart.
+	OnStepEnd func(ctx context.Context, step StepInfo, out any, err error)
+
+	// Called to execute a step, after OnStepStart. Call next to execute the step as usual, or return without calling it to replace the step. Hooks attached first are called first.
+	Intercept func(ctx context.Context, step StepInfo, in any, next Action) (any, error)
+
+	// Called by a retry step after an attempt fails and another attempt has been scheduled. The delay is the time that will be waited before the attempt, including jitter.
+	OnRetry func(ctx context.Context, step StepInfo, attempt int, delay time.Duration, err error)
+
+	// Called by a retry step when a retry is denied because its retry budget is exhausted, with the attempt that would have been made and the error that will be returned.
+	OnRetryDenied func(ctx context.Context, step StepInfo, attempt int, err error)
+
+	// Called by an if step after the condition has been evaluated, with the branch that will be executed.
+	OnBranch func(ctx context.Context, step StepInfo, branch bool)
+
+	// Called by a parallel step after all actions have completed and before the results are reduced.
+	OnParallelComplete func(ctx context.Context, step StepInfo, results []Result)
+}
+
+type hooksKey struct{}
+
+type stepInfoKey struct{}
+
+// Hooks attached to a context, in the order they were attached.
+type hookList []*Hooks
+
+// Returns a copy of the context with hooks attached. Hooks already attached to the context will continue to be called.
+func WithHooks(ctx context.Context, hooks *Hooks) context.Context {
+	if hooks == nil {
+		return ctx
+	}
+	existing := hooksFromContext(ctx)
+	combined := make(hookList, 0, len(existing)+1)
+	combined = append(combined, existing...)
+	combined = append(combined, hooks)
+	return context.WithValue(ctx, hooksKey{}, combined)
+}
+
+// Returns the hooks attached to a context.
+func hooksFromContext(ctx context.Context) hookList {
+	hooks, _ := ctx.Value(hooksKey{}).(hookList)
+	return hooks
+}
+
+// Calls OnStepStart for each hook in the order they were attached.
+func (h hookList) stepStart(ctx context.Context, step StepInfo, in any) context.Context {
+	for _, v := range h {
+		if v.OnStepStart != nil {
+			ctx = v.OnStepStart(ctx, step, in)
+		}
+	}
+	return ctx
+}
+
+// Executes a step through the Intercept function of each hook, with the hooks attached first called first.
+func (h hookList) intercept(ctx context.Context, step StepInfo, in any, run func(ctx context.Context, in any) (any, error)) (any, error) {
+	next := Action(ActionFunc(run))
+	for i := len(h) - 1; i >= 0; i-- {
+		if h[i].Intercept == nil {
+			continue
+		}
+		intercept, inner := h[i].Intercept, next
+		next = ActionFunc(func(ctx context.Context, in any) (any, error) {
+			return intercept(ctx, step, in, inner)
+		})
+	}
+	return next.Run(ctx, in)
+}
+
+// Calls OnStepEnd for each hook in the reverse order they were attached.
+func (h hookList) stepEnd(ctx context.Context, step StepInfo, out any, err error) {
+	for i := len(h) - 1; i >= 0; i-- {
+		if h[i].OnStepEnd != nil {
+			h[i].OnStepEnd(ctx, step, out, err)
+		}
+	}
+}
+
+// Calls OnRetry for each hook with the step executing in the context.
+func (h hookList) retry(ctx context.Context, attempt int, delay time.Duration, err error) {
+	step := stepInfoFromContext(ctx)
+	for _, v := range h {
+		if v.OnRetry != nil {
+			v.OnRetry(ctx, step, attempt, delay, err)
+		}
+	}
+}
+
+// Calls OnRetryDenied for each hook with the step executing in the context.
+func (h hookList) retryDenied(ctx context.Context, attempt int, err error) {
+	step := stepInfoFromContext(ctx)
+	for _, v := range h {
+		if v.OnRetryDenied != nil {
+			v.OnRetryDenied(ctx, step, attempt, err)
+		}
+	}
+}
+
+// Calls OnBranch for each hook with the step executing in the context.
+func (h hookList) branch(ctx context.Context, branch bool) {
+	step := stepInfoFromContext(ctx)
+	for _, v := range h {
+		if v.OnBranch != nil {
+			v.OnBranch(ctx, step, branch)
+		}
+	}
+}
+
+// Calls OnParallelComplete for each hook with the step executing in the context.
+func (h hookList) parallelComplete(ctx context.Context, results []Result) {
+	step := stepInfoFromContext(ctx)
+	for _, v := range h {
+		if v.OnParallelComplete != nil {
+			v.OnParallelComplete(ctx, step, results)
+		}
+	}
+}
+
+// Returns the step executing in the context. Only recorded when hooks are attached.
+func stepInfoFromContext(ctx context.Context) StepInfo {
+	step, _ := ctx.Value(stepInfoKey{}).(StepInfo)
+	return step
+}
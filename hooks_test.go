@@ -0,0 +1,158 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+type hookKey struct{}
+
+func Test_Unit_Hooks_StepStartAndEnd(t *testing.T) {
+	// arrange
+	actionErr := errors.New("test error")
+	action := Name("root", Sequential(
+		Name("add1", Do(func(in int) (int, error) {
+			return in + 1, nil
+		})),
+		Retry(Do(func(in int) (int, error) {
+			return in, actionErr
+		}), &RetryOptions{MaxRetries: 1}),
+	))
+
+	var events []string
+	var infos []StepInfo
+	var errs []error
+	hooks := &Hooks{
+		OnStepStart: func(ctx context.Context, step StepInfo, in any) context.Context {
+			events = append(events, "start "+string(step.Kind))
+			return ctx
+		},
+		OnStepEnd: func(ctx context.Context, step StepInfo, out any, err error) {
+			events = append(events, "end "+string(step.Kind))
+			infos = append(infos, step)
+			errs = append(errs, err)
+		},
+	}
+
+	// act
+	out, err := action.Run(WithHooks(context.Background(), hooks), 1)
+
+	// assert
+	assert.ErrorIs(t, err, actionErr)
+	assert.Equal(t, 2, out)
+	assert.Equal(t, []string{
+		"start sequential",
+		"start action",
+		"end action",
+		"start retry",
+		"start action",
+		"end action",
+		"start action",
+		"end action",
+		"end retry",
+		"end sequential",
+	}, events)
+	assert.Equal(t, StepInfo{Name: "add1", Kind: KindAction, Path: "root/add1[0]"}, infos[0])
+	assert.Equal(t, StepInfo{Kind: KindAction, Attempt: 0, Path: "root/retry[1]/action[0]"}, infos[1])
+	assert.Equal(t, StepInfo{Kind: KindAction, Attempt: 1, Path: "root/retry[1]/action[0]"}, infos[2])
+	assert.Equal(t, StepInfo{Kind: KindRetry, Path: "root/retry[1]"}, infos[3])
+	assert.Equal(t, StepInfo{Name: "root", Kind: KindSequential, Path: "root"}, infos[4])
+	assert.Equal(t, []error{nil, actionErr, actionErr}, errs[:3])
+	assert.ErrorIs(t, errs[3], actionErr)
+	assert.Equal(t, errs[3], errs[4])
+}
+
+func Test_Unit_Hooks_ContextFollowsTree(t *testing.T) {
+	// arrange
+	var parents []any
+	hooks := &Hooks{
+		OnStepStart: func(ctx context.Context, step StepInfo, in any) context.Context {
+			parents = append(parents, ctx.Value(hookKey{}))
+			return context.WithValue(ctx, hookKey{}, step.Name)
+		},
+	}
+	action := Name("outer", Sequential(Name("inner", NoOp())))
+
+	// act
+	_, err := action.Run(WithHooks(context.Background(), hooks), nil)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []any{nil, "outer"}, parents)
+}
+
+func Test_Unit_Hooks_Multiple(t *testing.T) {
+	// arrange
+	var events []string
+	newHooks := func(name string) *Hooks {
+		return &Hooks{
+			OnStepStart: func(ctx context.Context, step StepInfo, in any) context.Context {
+				events = append(events, "start "+name)
+				return ctx
+			},
+			OnStepEnd: func(ctx context.Context, step StepInfo, out any, err error) {
+				events = append(events, "end "+name)
+			},
+		}
+	}
+	ctx := WithHooks(context.Background(), newHooks("first"))
+	ctx = WithHooks(ctx, nil)
+	ctx = WithHooks(ctx, newHooks("second"))
+
+	// act
+	_, err := NoOp().Run(ctx, nil)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"start first", "start second", "end second", "end first"}, events)
+}
+
+func Test_Unit_Hooks_Intercept(t *testing.T) {
+	// arrange
+	var events []string
+	newHooks := func(name string) *Hooks {
+		return &Hooks{
+			Intercept: func(ctx context.Context, step StepInfo, in any, next Action) (any, error) {
+				if step.Name == "replaced" {
+					events = append(events, name+" replaced "+step.Path)
+					return 10, nil
+				}
+				events = append(events, name+" before "+step.Path)
+				out, err := next.Run(ctx, in)
+				events = append(events, name+" after "+step.Path)
+				return out, err
+			},
+		}
+	}
+	ctx := WithHooks(context.Background(), newHooks("first"))
+	ctx = WithHooks(ctx, newHooks("second"))
+	action := Sequential(
+		Name("replaced", Do(func(in int) (int, error) {
+			panic("should not be executed")
+		})),
+		Do(func(in int) (int, error) {
+			return in + 1, nil
+		}),
+	)
+
+	// act
+	out, err := action.Run(ctx, 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 11, out)
+	assert.Equal(t, []string{
+		"first before sequential",
+		"second before sequential",
+		"first replaced sequential/replaced[0]",
+		"first before sequential/action[1]",
+		"second before sequential/action[1]",
+		"second after sequential/action[1]",
+		"first after sequential/action[1]",
+		"second after sequential",
+		"first after sequential",
+	}, events)
+}
@@ -0,0 +1,196 @@
+package workflow
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Records the outputs of completed steps so a run can continue from its last checkpoint after a restart. Implementations must be safe for concurrent use.
+type Journal interface {
+	// Returns the recorded output of a step in a run. Returns false if the step has not been recorded.
+	Load(runID string, step string) ([]byte, bool, error)
+
+	// Records the output of a completed step in a run.
+	Save(runID string, step string, data []byte) error
+}
+
+type journalKey struct{}
+
+// A journal and the run it records.
+type journalRun struct {
+	journal Journal
+	runID   string
+}
+
+// Returns a copy of the context that records checkpoints to the journal under the run ID. Running a workflow again with the same journal and run ID skips every checkpoint that already completed and replays its recorded output instead.
+func WithJournal(ctx context.Context, journal Journal, runID string) context.Context {
+	return context.WithValue(ctx, journalKey{}, &journalRun{
+		journal: journal,
+		runID:   runID,
+	})
+}
+
+// Returns an action that records the output of a successful action to the journal in the context, if any, serialized as JSON. If the output has already been recorded for the run, the action is skipped and the recorded output is returned, decoded as T. The name must be unique within the workflow.
+func Checkpoint[T any](name string, action Action) Action {
+	if action == nil {
+		action = NoOp()
+	}
+
+	s := newStep(KindCheckpoint, []Action{action}, func(ctx context.Context, in any) (any, error) {
+		run, _ := ctx.Value(journalKey{}).(*journalRun)
+		if run == nil {
+			return runChild(ctx, 0, action, in)
+		}
+
+		data, ok, err := run.journal.Load(run.runID, name)
+		if err != nil {
+			return nil, fmt.Errorf("checkpoint %s: %w", name, err)
+		}
+		if ok {
+			var out T
+			if err := json.Unmarshal(data, &out); err != nil {
+				return nil, fmt.Errorf("checkpoint %s: %w", name, err)
+			}
+			return out, nil
+		}
+
+		out, err := runChild(ctx, 0, action, in)
+		if err != nil {
+			return out, err
+		}
+
+		data, err = json.Marshal(out)
+		if err != nil {
+			return out, fmt.Errorf("checkpoint %s: %w", name, err)
+		}
+		if err := run.journal.Save(run.runID, name, data); err != nil {
+			return out, fmt.Errorf("checkpoint %s: %w", name, err)
+		}
+		return out, nil
+	})
+	s.name = name
+	return s
+}
+
+// Journal that stores each run in a file in a directory, with one JSON line per completed step.
+type FileJournal struct {
+	dir  string
+	lock sync.Mutex
+}
+
+// A single line in a journal file.
+type journalEntry struct {
+	Step   string          `json:"step"`
+	Output json.RawMessage `json:"output"`
+}
+
+// Creates a journal that stores runs in the directory, creating it if necessary.
+func NewFileJournal(dir string) (*FileJournal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileJournal{dir: dir}, nil
+}
+
+// Returns the recorded output of a step in a run.
+func (j *FileJournal) Load(runID string, step string) ([]byte, bool, error) {
+	path, err := j.path(runID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	var data []byte
+	found := false
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// a partially written line means the process stopped while saving, so the step did not complete
+			continue
+		}
+		if entry.Step == step {
+			data, found = entry.Output, true
+		}
+	}
+	return data, found, scanner.Err()
+}
+
+// Appends the output of a completed step to the file for the run and syncs it to disk.
+func (j *FileJournal) Save(runID string, step string, data []byte) error {
+	path, err := j.path(runID)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(journalEntry{Step: step, Output: data})
+	if err != nil {
+		return err
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	torn, err := endsTorn(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if torn {
+		// end the partially written line, so the entry is not appended to it and skipped by Load
+		line = append([]byte{'\n'}, line...)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Returns true if the file does not end with a newline, which means the process stopped while saving the last line.
+func endsTorn(file *os.File) (bool, error) {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return false, err
+	}
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
+}
+
+// Returns the path of the file for a run.
+func (j *FileJournal) path(runID string) (string, error) {
+	if runID == "" || strings.ContainsAny(runID, `/\`) || runID == "." || runID == ".." {
+		return "", fmt.Errorf("invalid run id %q", runID)
+	}
+	return filepath.Join(j.dir, runID+".jsonl"), nil
+}
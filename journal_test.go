@@ -0,0 +1,168 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+type testOrder struct {
+	ID    string
+	Total int
+}
+
+func Test_Unit_Journal_Resume(t *testing.T) {
+	// arrange
+	journal, err := NewFileJournal(t.TempDir())
+	assert.NoError(t, err)
+
+	calls := map[string]int{}
+	fail := true
+	action := Sequential(
+		Checkpoint[testOrder]("create", Do(func(in string) (testOrder, error) {
+			calls["create"]++
+			return testOrder{ID: in, Total: 10}, nil
+		})),
+		Checkpoint[int]("charge", Do(func(in testOrder) (int, error) {
+			calls["charge"]++
+			return in.Total * 2, nil
+		})),
+		Checkpoint[string]("ship", Do(func(in int) (string, error) {
+			calls["ship"]++
+			if fail {
+				return "", errors.New("test error")
+			}
+			return "shipped", nil
+		})),
+	)
+	ctx := WithJournal(context.Background(), journal, "run-1")
+
+	// act
+	_, err = action.Run(ctx, "order-1")
+
+	// assert
+	assert.EqualError(t, err, "test error")
+	assert.Equal(t, map[string]int{"create": 1, "charge": 1, "ship": 1}, calls)
+
+	// act
+	fail = false
+	out, err := action.Run(ctx, "order-1")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "shipped", out)
+	assert.Equal(t, map[string]int{"create": 1, "charge": 1, "ship": 2}, calls)
+
+	data, ok, err := journal.Load("run-1", "create")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"ID": "order-1", "Total": 10}`, string(data))
+
+	// act
+	out, err = action.Run(WithJournal(context.Background(), journal, "run-2"), "order-2")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "shipped", out)
+	assert.Equal(t, map[string]int{"create": 2, "charge": 2, "ship": 3}, calls)
+}
+
+func Test_Unit_Journal_NoJournal(t *testing.T) {
+	// arrange
+	calls := 0
+	action := Checkpoint[int]("add1", Do(func(in int) (int, error) {
+		calls++
+		return in + 1, nil
+	}))
+
+	// act
+	out1, err1 := action.Run(context.Background(), 1)
+	out2, err2 := action.Run(context.Background(), 1)
+
+	// assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, 2, out1)
+	assert.Equal(t, 2, out2)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, &Node{Name: "add1", Kind: KindCheckpoint, Children: []*Node{{Kind: KindAction}}}, Describe(action))
+}
+
+func Test_Unit_Journal_FileJournal(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	journal, err := NewFileJournal(filepath.Join(dir, "journal"))
+	assert.NoError(t, err)
+
+	// act
+	_, ok, err := journal.Load("run", "step")
+
+	// assert
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// act
+	assert.NoError(t, journal.Save("run", "step", []byte(`1`)))
+	assert.NoError(t, journal.Save("run", "step", []byte(`2`)))
+	data, ok, err := journal.Load("run", "step")
+
+	// assert
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "2", string(data))
+
+	// act
+	file, err := os.OpenFile(filepath.Join(dir, "journal", "run.jsonl"), os.O_APPEND|os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	_, err = file.WriteString(`{"step": "partial", "out`)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+	_, ok, err = journal.Load("run", "partial")
+
+	// assert
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// act
+	assert.NoError(t, journal.Save("run", "recovered", []byte(`3`)))
+	data, ok, err = journal.Load("run", "recovered")
+
+	// assert
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "3", string(data))
+
+	// act
+	for _, v := range []string{"", ".", "..", "a/b", `a\b`} {
+		_, _, err = journal.Load(v, "step")
+		assert.Error(t, err, v)
+		assert.Error(t, journal.Save(v, "step", []byte(`1`)), v)
+	}
+}
+
+func Test_Unit_Journal_Errors(t *testing.T) {
+	// arrange
+	journal, err := NewFileJournal(t.TempDir())
+	assert.NoError(t, err)
+	ctx := WithJournal(context.Background(), journal, "run")
+	assert.NoError(t, journal.Save("run", "typed", []byte(`"not a number"`)))
+
+	// act
+	_, err = Checkpoint[int]("typed", NoOp()).Run(ctx, nil)
+
+	// assert
+	assert.ErrorContains(t, err, "checkpoint typed: json: cannot unmarshal string")
+
+	// act
+	out, err := Checkpoint[any]("unserializable", ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return make(chan int), nil
+	})).Run(ctx, nil)
+
+	// assert
+	assert.NotNil(t, out)
+	assert.ErrorContains(t, err, "checkpoint unserializable: json: unsupported type")
+}
@@ -0,0 +1,531 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Named actions, conditions, reducers and handlers that can be referenced by a workflow definition.
+type Registry struct {
+	actions    map[string]Action
+	conditions map[string]func(in any) (bool, error)
+	reducers   map[string]func(in []Result) (any, error)
+	handlers   map[string]func(any, error) (any, error)
+	steps      map[string]func(params map[string]any) (Action, error)
+}
+
+// Error found while loading a workflow definition.
+type LoadError struct {
+	// Line in the definition where the error was found, starting at 1. Can be 0 for a syntax error the parser did not report a line for.
+	Line int
+
+	// Column in the definition where the error was found, starting at 1. Can be 0 for a syntax error, since the parser only reports the line.
+	Column int
+
+	// Description of the error.
+	Msg string
+}
+
+// Formats the error with the line and column it was found at.
+func (e *LoadError) Error() string {
+	switch {
+	case e.Line == 0:
+		return e.Msg
+	case e.Column == 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	default:
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+	}
+}
+
+// Matches the syntax errors returned by the YAML parser, i.e. "yaml: line 2: did not find expected node content".
+var syntaxErrorPattern = regexp.MustCompile(`^yaml: (?:line (\d+): )?(.*)$`)
+
+// Converts an error returned by the YAML parser to a LoadError with the line it reports.
+func syntaxError(err error) *LoadError {
+	match := syntaxErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return &LoadError{Msg: err.Error()}
+	}
+	line, _ := strconv.Atoi(match[1])
+	return &LoadError{Line: line, Msg: match[2]}
+}
+
+// Creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		actions:    map[string]Action{},
+		conditions: map[string]func(in any) (bool, error){},
+		reducers:   map[string]func(in []Result) (any, error){},
+		handlers:   map[string]func(any, error) (any, error){},
+		steps:      map[string]func(params map[string]any) (Action, error){},
+	}
+}
+
+// Registers an action that can be referenced with "action: name".
+func (r *Registry) RegisterAction(name string, action Action) {
+	r.actions[name] = action
+}
+
+// Registers a condition that can be referenced by an if step.
+func (r *Registry) RegisterCondition(name string, condition func(in any) (bool, error)) {
+	r.conditions[name] = condition
+}
+
+// Registers a reduce function that can be referenced by a parallel step.
+func (r *Registry) RegisterReducer(name string, reduce func(in []Result) (any, error)) {
+	r.reducers[name] = reduce
+}
+
+// Registers a handle function that can be referenced by a catch or finally step.
+func (r *Registry) RegisterHandler(name string, handle func(any, error) (any, error)) {
+	r.handlers[name] = handle
+}
+
+// Registers a kind of step that is configured in the definition, i.e. "shell: {command: ls}". The build function is called with the decoded fields of the step and any error it returns is reported with the line of the step.
+func (r *Registry) RegisterStep(kind string, build func(params map[string]any) (Action, error)) {
+	r.steps[kind] = build
+}
+
+// Builds an action from a workflow definition in YAML or JSON. Every error found is returned, each as a *LoadError.
+//
+// Each step is a mapping with a single key naming its kind and an optional "name":
+//
+//	sequential:
+//	  - action: fetch
+//	  - name: fanout
+//	    parallel:
+//	      reduce: sum
+//	      actions:
+//	        - action: add1
+//	        - action: add2
+//	  - if:
+//	      condition: isOdd
+//	      then: {action: add1}
+//	      else: {noop: {}}
+//	  - retry:
+//	      action: {action: flaky}
+//	      maxRetries: 3
+//	      initialDelay: 200ms
+//	      backoffStrategy: exponential
+//	      backoffMultiplier: 1.5
+//	  - catch:
+//	      action: {action: flaky}
+//	      handler: recover
+func (r *Registry) Load(data []byte) (Action, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Join(syntaxError(err))
+	}
+	if len(doc.Content) == 0 {
+		return nil, errors.Join(&LoadError{Line: 1, Column: 1, Msg: "empty workflow definition"})
+	}
+
+	l := &loader{registry: r}
+	action := l.step(doc.Content[0])
+	if len(l.errs) > 0 {
+		return nil, errors.Join(l.errs...)
+	}
+	return action, nil
+}
+
+// Builds an action from a workflow definition file in YAML or JSON.
+func (r *Registry) LoadFile(path string) (Action, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	action, err := r.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return action, nil
+}
+
+// Builds actions from nodes and collects errors along the way.
+type loader struct {
+	registry *Registry
+	errs     []error
+}
+
+// Records an error at the position of a node.
+func (l *loader) errorf(n *yaml.Node, format string, args ...any) {
+	l.errs = append(l.errs, &LoadError{
+		Line:   n.Line,
+		Column: n.Column,
+		Msg:    fmt.Sprintf(format, args...),
+	})
+}
+
+// Returns the fields of a mapping node by key, recording an error for any key that is not allowed.
+func (l *loader) fields(n *yaml.Node, allowed ...string) map[string]*yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		l.errorf(n, "expected a mapping")
+		return nil
+	}
+
+	fields := map[string]*yaml.Node{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if !contains(allowed, key.Value) {
+			l.errorf(key, "unknown field %q, expected one of: %s", key.Value, strings.Join(allowed, ", "))
+			continue
+		}
+		if _, ok := fields[key.Value]; ok {
+			l.errorf(key, "duplicate field %q", key.Value)
+			continue
+		}
+		fields[key.Value] = value
+	}
+	return fields
+}
+
+// Returns a required field, recording an error if it is missing.
+func (l *loader) required(n *yaml.Node, fields map[string]*yaml.Node, key string) *yaml.Node {
+	value, ok := fields[key]
+	if !ok {
+		l.errorf(n, "missing required field %q", key)
+	}
+	return value
+}
+
+// Returns the value of a scalar node, recording an error if it is not a scalar.
+func (l *loader) scalar(n *yaml.Node) (string, bool) {
+	if n == nil {
+		return "", false
+	}
+	if n.Kind != yaml.ScalarNode {
+		l.errorf(n, "expected a scalar value")
+		return "", false
+	}
+	return n.Value, true
+}
+
+// Builds the action for a step node.
+func (l *loader) step(n *yaml.Node) Action {
+	kinds := append([]string{"action", "sequential", "parallel", "if", "catch", "finally", "retry", "noop"}, keys(l.registry.steps)...)
+	fields := l.fields(n, append([]string{"name"}, kinds...)...)
+	if fields == nil {
+		return nil
+	}
+
+	var kind string
+	for _, v := range kinds {
+		if _, ok := fields[v]; !ok {
+			continue
+		}
+		if kind != "" {
+			l.errorf(n, "step has more than one kind: %q and %q", kind, v)
+			return nil
+		}
+		kind = v
+	}
+	if kind == "" {
+		l.errorf(n, "step must have one of: %s", strings.Join(kinds, ", "))
+		return nil
+	}
+
+	body := fields[kind]
+	var action Action
+	switch kind {
+	case "action":
+		action = l.action(body)
+	case "sequential":
+		action = l.sequential(body)
+	case "parallel":
+		action = l.parallel(body)
+	case "if":
+		action = l.ifStep(body)
+	case "catch":
+		action = l.catch(body)
+	case "finally":
+		action = l.finally(body)
+	case "retry":
+		action = l.retry(body)
+	case "noop":
+		action = NoOp()
+	default:
+		action = l.custom(kind, body)
+	}
+
+	if name, ok := fields["name"]; ok {
+		if value, ok := l.scalar(name); ok && action != nil {
+			action = Name(value, action)
+		}
+	}
+	return action
+}
+
+// Looks up a registered action. The step is named after the action unless a name is given.
+func (l *loader) action(n *yaml.Node) Action {
+	name, ok := l.scalar(n)
+	if !ok {
+		return nil
+	}
+	action, ok := l.registry.actions[name]
+	if !ok {
+		l.errorf(n, "unknown action %q", name)
+		return nil
+	}
+	return Name(name, action)
+}
+
+// Builds the actions in a sequence node.
+func (l *loader) steps(n *yaml.Node) []Action {
+	if n.Kind != yaml.SequenceNode {
+		l.errorf(n, "expected a list of steps")
+		return nil
+	}
+	var actions []Action
+	for _, v := range n.Content {
+		actions = append(actions, l.step(v))
+	}
+	return actions
+}
+
+// Builds a step of a registered kind.
+func (l *loader) custom(kind string, n *yaml.Node) Action {
+	params := map[string]any{}
+	if err := n.Decode(&params); err != nil {
+		l.errorf(n, "expected a mapping of %s fields", kind)
+		return nil
+	}
+	action, err := l.registry.steps[kind](params)
+	if err != nil {
+		l.errorf(n, "invalid %s step: %v", kind, err)
+		return nil
+	}
+	return Name(kind, action)
+}
+
+func (l *loader) sequential(n *yaml.Node) Action {
+	return Sequential(l.steps(n)...)
+}
+
+func (l *loader) parallel(n *yaml.Node) Action {
+	fields := l.fields(n, "reduce", "actions", "clone")
+	if fields == nil {
+		return nil
+	}
+
+	var reduce func(in []Result) (any, error)
+	if name, ok := l.scalar(l.required(n, fields, "reduce")); ok {
+		reduce, ok = l.registry.reducers[name]
+		if !ok {
+			l.errorf(fields["reduce"], "unknown reducer %q", name)
+		}
+	}
+
+	var actions []Action
+	if value := l.required(n, fields, "actions"); value != nil {
+		actions = l.steps(value)
+	}
+
+	opts := &ParallelOptions{}
+	if value, ok := fields["clone"]; ok && l.bool(value) {
+		opts.Clone = DeepCopy
+	}
+
+	return ParallelWithOptions(opts, reduce, actions...)
+}
+
+func (l *loader) ifStep(n *yaml.Node) Action {
+	fields := l.fields(n, "condition", "then", "else")
+	if fields == nil {
+		return nil
+	}
+
+	var condition func(in any) (bool, error)
+	if name, ok := l.scalar(l.required(n, fields, "condition")); ok {
+		condition, ok = l.registry.conditions[name]
+		if !ok {
+			l.errorf(fields["condition"], "unknown condition %q", name)
+		}
+	}
+
+	var ifTrue, ifFalse Action
+	if value := l.required(n, fields, "then"); value != nil {
+		ifTrue = l.step(value)
+	}
+	if value := l.required(n, fields, "else"); value != nil {
+		ifFalse = l.step(value)
+	}
+
+	return If(condition, ifTrue, ifFalse)
+}
+
+// Returns the action and handler of a catch or finally step.
+func (l *loader) handled(n *yaml.Node) (Action, func(any, error) (any, error)) {
+	fields := l.fields(n, "action", "handler")
+	if fields == nil {
+		return nil, nil
+	}
+
+	var action Action
+	if value := l.required(n, fields, "action"); value != nil {
+		action = l.step(value)
+	}
+
+	var handle func(any, error) (any, error)
+	if name, ok := l.scalar(l.required(n, fields, "handler")); ok {
+		handle, ok = l.registry.handlers[name]
+		if !ok {
+			l.errorf(fields["handler"], "unknown handler %q", name)
+		}
+	}
+
+	return action, handle
+}
+
+func (l *loader) catch(n *yaml.Node) Action {
+	action, handle := l.handled(n)
+	return Catch(action, handle)
+}
+
+func (l *loader) finally(n *yaml.Node) Action {
+	action, handle := l.handled(n)
+	return Finally(action, handle)
+}
+
+func (l *loader) retry(n *yaml.Node) Action {
+	fields := l.fields(n, "action", "maxRetries", "initialDelay", "maxDelay", "maxElapsedTime", "stopAtDeadline", "jitter", "backoffStrategy", "backoffIncrement", "backoffMultiplier")
+	if fields == nil {
+		return nil
+	}
+
+	var action Action
+	if value := l.required(n, fields, "action"); value != nil {
+		action = l.step(value)
+	}
+
+	opts := &RetryOptions{}
+	if value, ok := fields["maxRetries"]; ok {
+		opts.MaxRetries = l.int(value)
+	}
+	if value, ok := fields["initialDelay"]; ok {
+		opts.InitialDelay = l.duration(value)
+	}
+	if value, ok := fields["maxDelay"]; ok {
+		opts.MaxDelay = l.duration(value)
+	}
+	if value, ok := fields["maxElapsedTime"]; ok {
+		opts.MaxElapsedTime = l.duration(value)
+	}
+	if value, ok := fields["stopAtDeadline"]; ok {
+		opts.StopAtDeadline = l.bool(value)
+	}
+	if value, ok := fields["jitter"]; ok {
+		opts.Jitter = l.duration(value)
+	}
+
+	var increment time.Duration
+	if value, ok := fields["backoffIncrement"]; ok {
+		increment = l.duration(value)
+	}
+	multiplier := 2.0
+	if value, ok := fields["backoffMultiplier"]; ok {
+		multiplier = l.float(value)
+	}
+	if value, ok := fields["backoffStrategy"]; ok {
+		strategies := map[string]func(b Backoff) time.Duration{
+			"none":               BackoffStrategyNone(),
+			"linear":             BackoffStrategyLinear(increment),
+			"exponential":        BackoffStrategyMultiplier(multiplier),
+			"fibonacci":          BackoffStrategyFibonacci(),
+			"fullJitter":         BackoffStrategyFullJitter(),
+			"decorrelatedJitter": BackoffStrategyDecorrelatedJitter(),
+		}
+		if name, ok := l.scalar(value); ok {
+			opts.BackoffStrategy, ok = strategies[name]
+			if !ok {
+				l.errorf(value, "unknown backoff strategy %q, expected one of: %s", name, strings.Join(keys(strategies), ", "))
+			}
+		}
+	}
+
+	return Retry(action, opts)
+}
+
+// Parses a non-negative integer.
+func (l *loader) int(n *yaml.Node) int {
+	value, ok := l.scalar(n)
+	if !ok {
+		return 0
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		l.errorf(n, "expected a non-negative integer, got %q", value)
+		return 0
+	}
+	return i
+}
+
+// Parses true or false.
+func (l *loader) bool(n *yaml.Node) bool {
+	value, ok := l.scalar(n)
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		l.errorf(n, "expected true or false, got %q", value)
+		return false
+	}
+	return b
+}
+
+// Parses a non-negative number.
+func (l *loader) float(n *yaml.Node) float64 {
+	value, ok := l.scalar(n)
+	if !ok {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		l.errorf(n, "expected a non-negative number, got %q", value)
+		return 0
+	}
+	return f
+}
+
+// Parses a duration such as "200ms".
+func (l *loader) duration(n *yaml.Node) time.Duration {
+	value, ok := l.scalar(n)
+	if !ok {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		l.errorf(n, "expected a non-negative duration such as \"200ms\", got %q", value)
+		return 0
+	}
+	return d
+}
+
+// Returns true if the value is in the slice.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the keys of a map in sorted order.
+func keys[T any](m map[string]T) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
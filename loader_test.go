@@ -0,0 +1,260 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func newTestRegistry() *Registry {
+	registry := NewRegistry()
+	registry.RegisterAction("add1", Do(func(in int) (int, error) {
+		return in + 1, nil
+	}))
+	registry.RegisterAction("add2", Do(func(in int) (int, error) {
+		return in + 2, nil
+	}))
+	registry.RegisterAction("fail", Do(func(in int) (int, error) {
+		return in, errors.New("test error")
+	}))
+	registry.RegisterCondition("isOdd", func(in any) (bool, error) {
+		return in.(int)%2 == 1, nil
+	})
+	registry.RegisterReducer("sum", func(in []Result) (any, error) {
+		total := 0
+		for _, v := range in {
+			total += v.Out.(int)
+		}
+		return total, nil
+	})
+	registry.RegisterHandler("recover", func(out any, err error) (any, error) {
+		return out.(int) + 10, nil
+	})
+	return registry
+}
+
+func Test_Unit_Loader_YAML(t *testing.T) {
+	// arrange
+	registry := newTestRegistry()
+	definition := `
+name: root
+sequential:
+  - action: add1             # 1 + 1 == 2
+  - name: fanout
+    parallel:                # 3 + 4 == 7
+      reduce: sum
+      clone: true
+      actions:
+        - action: add1
+        - action: add2
+  - if:
+      condition: isOdd       # 7 is odd
+      then:
+        catch:
+          action: {action: fail}
+          handler: recover   # 7 + 10 == 17
+      else: {noop: {}}
+  - retry:
+      action: {action: add2} # 17 + 2 == 19
+      maxRetries: 2
+      initialDelay: 10ms
+      maxDelay: 1s
+      maxElapsedTime: 1m
+      stopAtDeadline: true
+      jitter: 1ms
+      backoffStrategy: linear
+      backoffIncrement: 5ms
+  - finally:
+      action: {action: add1} # 19 + 1 == 20
+      handler: recover       # 20 + 10 == 30
+`
+
+	// act
+	action, err := registry.Load([]byte(definition))
+	assert.NoError(t, err)
+	out, err := action.Run(context.Background(), 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 30, out)
+
+	node := Describe(action)
+	assert.Equal(t, "root", node.Name)
+	assert.Equal(t, KindSequential, node.Kind)
+	assert.Equal(t, "add1", node.Children[0].Name)
+	assert.Equal(t, "fanout", node.Children[1].Name)
+	assert.Equal(t, KindCatch, node.Children[2].Children[0].Kind)
+
+	retry := node.Children[3].Retry
+	assert.Equal(t, 2, retry.MaxRetries)
+	assert.Equal(t, "10ms", retry.InitialDelay.String())
+	assert.Equal(t, "1s", retry.MaxDelay.String())
+	assert.Equal(t, "1m0s", retry.MaxElapsedTime.String())
+	assert.True(t, retry.StopAtDeadline)
+	assert.Equal(t, "1ms", retry.Jitter.String())
+	assert.Equal(t, "15ms", retry.BackoffStrategy(Backoff{Attempt: 2, InitialDelay: retry.InitialDelay, Delay: retry.InitialDelay}).String())
+}
+
+func Test_Unit_Loader_JSON(t *testing.T) {
+	// arrange
+	registry := newTestRegistry()
+	definition := `{
+  "sequential": [
+    {"action": "add1"},
+    {"parallel": {"reduce": "sum", "actions": [{"action": "add1"}, {"action": "add2"}]}}
+  ]
+}`
+
+	// act
+	action, err := registry.Load([]byte(definition))
+	assert.NoError(t, err)
+	out, err := action.Run(context.Background(), 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 7, out)
+}
+
+func Test_Unit_Loader_RegisterStep(t *testing.T) {
+	// arrange
+	registry := newTestRegistry()
+	registry.RegisterStep("add", func(params map[string]any) (Action, error) {
+		amount, ok := params["amount"].(int)
+		if !ok {
+			return nil, errors.New("amount must be an integer")
+		}
+		return Do(func(in int) (int, error) {
+			return in + amount, nil
+		}), nil
+	})
+
+	// act
+	action, err := registry.Load([]byte("sequential:\n  - action: add1\n  - add: {amount: 5}\n"))
+	assert.NoError(t, err)
+	out, err := action.Run(context.Background(), 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 7, out)
+	assert.Equal(t, "add", Describe(action).Children[1].Name)
+
+	_, err = registry.Load([]byte("sequential:\n  - add: {amount: five}\n  - add: [1]\n"))
+	assert.EqualError(t, err, "line 2, column 10: invalid add step: amount must be an integer\nline 3, column 10: expected a mapping of add fields")
+}
+
+func Test_Unit_Loader_File(t *testing.T) {
+	// arrange
+	registry := newTestRegistry()
+	path := filepath.Join(t.TempDir(), "workflow.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("action: add1\n"), 0o600))
+
+	// act
+	action, err := registry.LoadFile(path)
+	assert.NoError(t, err)
+	out, err := action.Run(context.Background(), 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, out)
+
+	_, err = registry.LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func Test_Unit_Loader_Errors(t *testing.T) {
+	// arrange
+	registry := newTestRegistry()
+
+	testCases := []struct {
+		name       string
+		definition string
+		errs       []string
+	}{
+		{
+			name:       "empty",
+			definition: "",
+			errs:       []string{"line 1, column 1: empty workflow definition"},
+		},
+		{
+			name:       "not a mapping",
+			definition: "- action: add1",
+			errs:       []string{"line 1, column 1: expected a mapping"},
+		},
+		{
+			name:       "unknown names",
+			definition: "sequential:\n  - action: missing\n  - parallel:\n      reduce: product\n      actions: []\n  - if:\n      condition: isEven\n      then: {noop: {}}\n      else: {noop: {}}\n  - catch:\n      action: {noop: {}}\n      handler: ignore\n",
+			errs: []string{
+				`line 2, column 13: unknown action "missing"`,
+				`line 4, column 15: unknown reducer "product"`,
+				`line 7, column 18: unknown condition "isEven"`,
+				`line 12, column 16: unknown handler "ignore"`,
+			},
+		},
+		{
+			name:       "malformed step",
+			definition: "sequential:\n  - action: add1\n    noop: {}\n  - {}\n  - wait: 1s\n",
+			errs: []string{
+				`line 2, column 5: step has more than one kind: "action" and "noop"`,
+				`line 4, column 5: step must have one of: action, sequential, parallel, if, catch, finally, retry, noop`,
+				`line 5, column 5: unknown field "wait", expected one of: name, action, sequential, parallel, if, catch, finally, retry, noop`,
+				`line 5, column 5: step must have one of: action, sequential, parallel, if, catch, finally, retry, noop`,
+			},
+		},
+		{
+			name:       "missing fields",
+			definition: "if:\n  condition: isOdd\n",
+			errs: []string{
+				`line 2, column 3: missing required field "then"`,
+				`line 2, column 3: missing required field "else"`,
+			},
+		},
+		{
+			name:       "bad retry options",
+			definition: "retry:\n  action: {action: add1}\n  maxRetries: -1\n  initialDelay: soon\n  backoffStrategy: random\n  backoffMultiplier: fast\n",
+			errs: []string{
+				`line 3, column 15: expected a non-negative integer, got "-1"`,
+				`line 4, column 17: expected a non-negative duration such as "200ms", got "soon"`,
+				`line 6, column 22: expected a non-negative number, got "fast"`,
+				`line 5, column 20: unknown backoff strategy "random", expected one of: decorrelatedJitter, exponential, fibonacci, fullJitter, linear, none`,
+			},
+		},
+		{
+			name:       "wrong node types",
+			definition: "sequential: {action: add1}\n",
+			errs:       []string{`line 1, column 13: expected a list of steps`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			action, err := registry.Load([]byte(tc.definition))
+
+			// assert
+			assert.Nil(t, action)
+			var loadErr *LoadError
+			assert.ErrorAs(t, err, &loadErr)
+
+			var messages []string
+			for _, v := range err.(interface{ Unwrap() []error }).Unwrap() {
+				messages = append(messages, v.Error())
+			}
+			assert.Equal(t, tc.errs, messages)
+		})
+	}
+}
+
+func Test_Unit_Loader_SyntaxError(t *testing.T) {
+	// act
+	_, err := newTestRegistry().Load([]byte("sequential:\n  - action: add1\n  - [\n"))
+
+	// assert
+	var loadErr *LoadError
+	assert.ErrorAs(t, err, &loadErr)
+	assert.Equal(t, 3, loadErr.Line)
+	assert.Equal(t, "line 3: did not find expected node content", err.Error())
+}
@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Identifies an event that can be logged.
+type LogEvent string
+
+const (
+	LogEventStepStart        LogEvent = "step started"
+	LogEventStepSuccess      LogEvent = "step succeeded"
+	LogEventStepFailure      LogEvent = "step failed"
+	LogEventRetryScheduled   LogEvent = "retry scheduled"
+	LogEventRetryDenied      LogEvent = "retry denied"
+	LogEventBranchChosen     LogEvent = "branch chosen"
+	LogEventParallelComplete LogEvent = "parallel completed"
+)
+
+// Options for configuring logging.
+type LogOptions struct {
+	// Level to log each event at. Events that are not in the map are logged at their default level: failures and retries at warn and everything else at debug.
+	Levels map[LogEvent]slog.Level
+
+	// Optional function to replace inputs, outputs and errors before they are logged, so sensitive values never reach the logs. The key is "in", "out" or "error". Can set to nil to log values as-is.
+	Redact func(step StepInfo, key string, value any) any
+}
+
+// Default level for each event.
+var defaultLogLevels = map[LogEvent]slog.Level{
+	LogEventStepStart:        slog.LevelDebug,
+	LogEventStepSuccess:      slog.LevelDebug,
+	LogEventStepFailure:      slog.LevelWarn,
+	LogEventRetryScheduled:   slog.LevelWarn,
+	LogEventRetryDenied:      slog.LevelWarn,
+	LogEventBranchChosen:     slog.LevelDebug,
+	LogEventParallelComplete: slog.LevelDebug,
+}
+
+// Returns a copy of the context that logs a structured record for each step executed with it.
+func WithLogger(ctx context.Context, logger *slog.Logger, opts *LogOptions) context.Context {
+	return WithHooks(ctx, LogHooks(logger, opts))
+}
+
+// Returns hooks that log a structured record for each step.
+func LogHooks(logger *slog.Logger, opts *LogOptions) *Hooks {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if opts == nil {
+		opts = &LogOptions{}
+	}
+
+	level := func(event LogEvent) slog.Level {
+		if level, ok := opts.Levels[event]; ok {
+			return level
+		}
+		return defaultLogLevels[event]
+	}
+
+	value := func(step StepInfo, key string, value any) slog.Attr {
+		if opts.Redact != nil {
+			value = opts.Redact(step, key, value)
+		}
+		return slog.Any(key, value)
+	}
+
+	log := func(ctx context.Context, event LogEvent, step StepInfo, attrs ...slog.Attr) {
+		attrs = append([]slog.Attr{
+			slog.String("step", step.Name),
+			slog.String("kind", string(step.Kind)),
+			slog.Int("attempt", step.Attempt),
+		}, attrs...)
+		logger.LogAttrs(ctx, level(event), string(event), attrs...)
+	}
+
+	type startKey struct{}
+
+	return &Hooks{
+		OnStepStart: func(ctx context.Context, step StepInfo, in any) context.Context {
+			log(ctx, LogEventStepStart, step, value(step, "in", in))
+			return context.WithValue(ctx, startKey{}, time.Now())
+		},
+		OnStepEnd: func(ctx context.Context, step StepInfo, out any, err error) {
+			start, _ := ctx.Value(startKey{}).(time.Time)
+			elapsed := slog.Duration("elapsed", time.Since(start))
+			if err != nil {
+				log(ctx, LogEventStepFailure, step, elapsed, value(step, "out", out), value(step, "error", err))
+				return
+			}
+			log(ctx, LogEventStepSuccess, step, elapsed, value(step, "out", out))
+		},
+		OnRetry: func(ctx context.Context, step StepInfo, attempt int, delay time.Duration, err error) {
+			log(ctx, LogEventRetryScheduled, step, slog.Int("next_attempt", attempt), slog.Duration("delay", delay), value(step, "error", err))
+		},
+		OnRetryDenied: func(ctx context.Context, step StepInfo, attempt int, err error) {
+			log(ctx, LogEventRetryDenied, step, slog.Int("next_attempt", attempt), value(step, "error", err))
+		},
+		OnBranch: func(ctx context.Context, step StepInfo, branch bool) {
+			log(ctx, LogEventBranchChosen, step, slog.Bool("branch", branch))
+		},
+		OnParallelComplete: func(ctx context.Context, step StepInfo, results []Result) {
+			failed := 0
+			for _, v := range results {
+				if v.Err != nil {
+					failed++
+				}
+			}
+			log(ctx, LogEventParallelComplete, step, slog.Int("completed", len(results)), slog.Int("failed", failed))
+		},
+	}
+}
@@ -0,0 +1,129 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func readLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func Test_Unit_Logging_Events(t *testing.T) {
+	// arrange
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	actionErr := errors.New("test error")
+	numErrs := 1
+	sum := func(in []Result) (int, error) {
+		return len(in), nil
+	}
+	action := Name("root", Sequential(
+		Parallel(sum, NoOp(), NoOp()),
+		If(func(in int) (bool, error) {
+			return in == 2, nil
+		}, NoOp(), NoOp()),
+		Retry(Name("flaky", ActionFunc(func(ctx context.Context, in any) (any, error) {
+			if numErrs > 0 {
+				numErrs--
+				return nil, actionErr
+			}
+			return "done", nil
+		})), &RetryOptions{MaxRetries: 1}),
+	))
+
+	// act
+	out, err := action.Run(WithLogger(context.Background(), logger, nil), 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "done", out)
+
+	var messages []string
+	records := readLogs(t, &buf)
+	for _, v := range records {
+		messages = append(messages, v["level"].(string)+" "+v["msg"].(string)+" "+v["kind"].(string))
+	}
+	assert.Equal(t, []string{
+		"DEBUG step started sequential",
+		"DEBUG step started parallel",
+		"DEBUG step started noop",
+		"DEBUG step succeeded noop",
+		"DEBUG step started noop",
+		"DEBUG step succeeded noop",
+		"DEBUG parallel completed parallel",
+		"DEBUG step succeeded parallel",
+		"DEBUG step started if",
+		"DEBUG branch chosen if",
+		"DEBUG step started noop",
+		"DEBUG step succeeded noop",
+		"DEBUG step succeeded if",
+		"DEBUG step started retry",
+		"DEBUG step started action",
+		"WARN step failed action",
+		"WARN retry scheduled retry",
+		"DEBUG step started action",
+		"DEBUG step succeeded action",
+		"DEBUG step succeeded retry",
+		"DEBUG step succeeded sequential",
+	}, messages)
+
+	assert.Equal(t, "root", records[0]["step"])
+	assert.Equal(t, float64(1), records[0]["in"])
+	assert.Equal(t, float64(2), records[6]["completed"])
+	assert.Equal(t, float64(0), records[6]["failed"])
+	assert.Equal(t, true, records[9]["branch"])
+	assert.Equal(t, "test error", records[15]["error"])
+	assert.Equal(t, float64(1), records[16]["next_attempt"])
+	assert.Contains(t, records[16], "delay")
+	assert.Equal(t, float64(1), records[17]["attempt"])
+	assert.Equal(t, "done", records[20]["out"])
+	assert.Contains(t, records[20], "elapsed")
+}
+
+func Test_Unit_Logging_LevelsAndRedact(t *testing.T) {
+	// arrange
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	opts := &LogOptions{
+		Levels: map[LogEvent]slog.Level{
+			LogEventStepStart:   slog.LevelInfo,
+			LogEventStepFailure: slog.LevelError,
+		},
+		Redact: func(step StepInfo, key string, value any) any {
+			return "redacted " + key
+		},
+	}
+	action := Do(func(in string) (string, error) {
+		return "secret output", errors.New("secret error")
+	})
+
+	// act
+	_, err := action.Run(WithLogger(context.Background(), logger, opts), "secret input")
+
+	// assert
+	assert.Error(t, err)
+	assert.NotContains(t, buf.String(), "secret")
+
+	records := readLogs(t, &buf)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "INFO", records[0]["level"])
+	assert.Equal(t, "redacted in", records[0]["in"])
+	assert.Equal(t, "ERROR", records[1]["level"])
+	assert.Equal(t, "redacted out", records[1]["out"])
+	assert.Equal(t, "redacted error", records[1]["error"])
+}
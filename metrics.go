@@ -0,0 +1,52 @@
+package workflow
+
+import (
+	"context"
+	"time"
+)
+
+// Records measurements as a workflow executes. Implementations must be safe for concurrent use.
+type Metrics interface {
+	// Called each time a step is invoked.
+	StepStarted(step StepInfo)
+
+	// Called after a step completes with how long it took and the error returned, if any.
+	StepEnded(step StepInfo, elapsed time.Duration, err error)
+
+	// Called when a retry step schedules another attempt, with the delay before the attempt.
+	RetryScheduled(step StepInfo, delay time.Duration)
+
+	// Called when a retry step does not retry because its retry budget is exhausted.
+	RetryDenied(step StepInfo)
+}
+
+// Returns a copy of the context that records metrics for each step executed with it.
+func WithMetrics(ctx context.Context, metrics Metrics) context.Context {
+	return WithHooks(ctx, MetricsHooks(metrics))
+}
+
+// Returns hooks that record metrics for each step.
+func MetricsHooks(metrics Metrics) *Hooks {
+	if metrics == nil {
+		return nil
+	}
+
+	type startKey struct{}
+
+	return &Hooks{
+		OnStepStart: func(ctx context.Context, step StepInfo, in any) context.Context {
+			metrics.StepStarted(step)
+			return context.WithValue(ctx, startKey{}, time.Now())
+		},
+		OnStepEnd: func(ctx context.Context, step StepInfo, out any, err error) {
+			start, _ := ctx.Value(startKey{}).(time.Time)
+			metrics.StepEnded(step, time.Since(start), err)
+		},
+		OnRetry: func(ctx context.Context, step StepInfo, attempt int, delay time.Duration, err error) {
+			metrics.RetryScheduled(step, delay)
+		},
+		OnRetryDenied: func(ctx context.Context, step StepInfo, attempt int, err error) {
+			metrics.RetryDenied(step)
+		},
+	}
+}
@@ -0,0 +1,98 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+type testMetrics struct {
+	lock    sync.Mutex
+	started []StepInfo
+	ended   []error
+	delays  []time.Duration
+	denied  []StepInfo
+}
+
+func (m *testMetrics) StepStarted(step StepInfo) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.started = append(m.started, step)
+}
+
+func (m *testMetrics) StepEnded(step StepInfo, elapsed time.Duration, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ended = append(m.ended, err)
+}
+
+func (m *testMetrics) RetryScheduled(step StepInfo, delay time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.delays = append(m.delays, delay)
+}
+
+func (m *testMetrics) RetryDenied(step StepInfo) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.denied = append(m.denied, step)
+}
+
+func Test_Unit_Metrics_WithMetrics(t *testing.T) {
+	// arrange
+	metrics := &testMetrics{}
+	actionErr := errors.New("test error")
+	action := Retry(Do(func(in int) (int, error) {
+		return 0, actionErr
+	}), &RetryOptions{
+		MaxRetries:   1,
+		InitialDelay: time.Millisecond,
+	})
+
+	// act
+	_, err := action.Run(WithMetrics(context.Background(), metrics), 1)
+
+	// assert
+	assert.ErrorIs(t, err, actionErr)
+	assert.Equal(t, []StepInfo{
+		{Kind: KindRetry, Path: "retry"},
+		{Kind: KindAction, Path: "retry/action[0]"},
+		{Kind: KindAction, Attempt: 1, Path: "retry/action[0]"},
+	}, metrics.started)
+	assert.Equal(t, []error{actionErr, actionErr}, metrics.ended[:2])
+	assert.ErrorIs(t, metrics.ended[2], actionErr)
+	assert.Equal(t, []time.Duration{time.Millisecond}, metrics.delays)
+}
+
+func Test_Unit_Metrics_RetryDenied(t *testing.T) {
+	// arrange
+	metrics := &testMetrics{}
+	budget := NewRetryBudget(&RetryBudgetOptions{Ratio: 0, MinRetries: 0})
+	actionErr := errors.New("test error")
+	action := Name("flaky", Retry(Do(func(in int) (int, error) {
+		return 0, actionErr
+	}), &RetryOptions{MaxRetries: 3, Budget: budget}))
+
+	// act
+	_, err := action.Run(WithMetrics(context.Background(), metrics), 1)
+
+	// assert
+	assert.Equal(t, actionErr, err)
+	assert.Empty(t, metrics.delays)
+	assert.Equal(t, []StepInfo{{Name: "flaky", Kind: KindRetry, Path: "flaky"}}, metrics.denied)
+}
+
+func Test_Unit_Metrics_Nil(t *testing.T) {
+	// act
+	ctx := WithMetrics(context.Background(), nil)
+	out, err := NoOp().Run(ctx, 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Nil(t, out)
+	assert.Empty(t, hooksFromContext(ctx))
+}
@@ -0,0 +1,74 @@
+// Package otelworkflow creates OpenTelemetry spans for the steps of a workflow.
+package otelworkflow
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	workflow "github.com/eleniums/go-workflow"
+)
+
+// Name of the instrumentation scope used when creating a tracer.
+const ScopeName = "github.com/eleniums/go-workflow/otelworkflow"
+
+// Attribute keys recorded on every span.
+const (
+	StepNameKey     = attribute.Key("workflow.step.name")
+	StepKindKey     = attribute.Key("workflow.step.kind")
+	RetryAttemptKey = attribute.Key("workflow.retry.attempt")
+)
+
+// Options for configuring tracing.
+type Options struct {
+	// Provider used to create a tracer. Can set to nil to use the global tracer provider.
+	TracerProvider trace.TracerProvider
+}
+
+// Returns hooks that create a span for every step. Spans are started from the span in the context, so the parent/child relationships follow the combinator tree.
+func Hooks(opts *Options) *workflow.Hooks {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	provider := opts.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	tracer := provider.Tracer(ScopeName)
+
+	return &workflow.Hooks{
+		OnStepStart: func(ctx context.Context, step workflow.StepInfo, in any) context.Context {
+			ctx, _ = tracer.Start(ctx, spanName(step), trace.WithAttributes(
+				StepNameKey.String(step.Name),
+				StepKindKey.String(string(step.Kind)),
+				RetryAttemptKey.Int(step.Attempt),
+			))
+			return ctx
+		},
+		OnStepEnd: func(ctx context.Context, step workflow.StepInfo, out any, err error) {
+			span := trace.SpanFromContext(ctx)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		},
+	}
+}
+
+// Returns a copy of the context that creates a span for every step executed with it.
+func WithTracing(ctx context.Context, opts *Options) context.Context {
+	return workflow.WithHooks(ctx, Hooks(opts))
+}
+
+// Uses the step name if one was given, otherwise the kind of step.
+func spanName(step workflow.StepInfo) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return string(step.Kind)
+}
@@ -0,0 +1,157 @@
+package otelworkflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	workflow "github.com/eleniums/go-workflow"
+)
+
+func newProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return provider, exporter
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	for _, v := range spans {
+		if v.Name == name {
+			return v
+		}
+	}
+	t.Fatalf("span %q not found", name)
+	return tracetest.SpanStub{}
+}
+
+func attr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, v := range span.Attributes {
+		if v.Key == key {
+			return v.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func Test_Unit_OtelWorkflow_ParentChild(t *testing.T) {
+	// arrange
+	provider, exporter := newProvider()
+	add1 := func(in int) (int, error) {
+		return in + 1, nil
+	}
+	isOdd := func(in int) (bool, error) {
+		return in%2 == 1, nil
+	}
+	sum := func(in []workflow.Result) (int, error) {
+		total := 0
+		for _, v := range in {
+			total += v.Out.(int)
+		}
+		return total, nil
+	}
+	action := workflow.Name("root", workflow.Sequential(
+		workflow.Name("first", workflow.Do(add1)),
+		workflow.Name("fanout", workflow.Parallel(sum,
+			workflow.Name("left", workflow.Do(add1)),
+			workflow.Name("right", workflow.Do(add1)),
+		)),
+		workflow.Name("branch", workflow.If(isOdd,
+			workflow.Name("odd", workflow.Do(add1)),
+			workflow.Name("even", workflow.Do(add1)),
+		)),
+	))
+
+	// act
+	ctx := WithTracing(context.Background(), &Options{TracerProvider: provider})
+	out, err := action.Run(ctx, 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 7, out)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 7)
+
+	root := findSpan(t, spans, "root")
+	assert.False(t, root.Parent.IsValid())
+	assert.Equal(t, "sequential", attr(root, StepKindKey).AsString())
+
+	for _, name := range []string{"first", "fanout", "branch"} {
+		span := findSpan(t, spans, name)
+		assert.Equal(t, root.SpanContext.SpanID(), span.Parent.SpanID(), name)
+		assert.Equal(t, name, attr(span, StepNameKey).AsString())
+	}
+
+	fanout := findSpan(t, spans, "fanout")
+	assert.Equal(t, "parallel", attr(fanout, StepKindKey).AsString())
+	for _, name := range []string{"left", "right"} {
+		span := findSpan(t, spans, name)
+		assert.Equal(t, fanout.SpanContext.SpanID(), span.Parent.SpanID(), name)
+	}
+
+	branch := findSpan(t, spans, "branch")
+	assert.Equal(t, "if", attr(branch, StepKindKey).AsString())
+	even := findSpan(t, spans, "even")
+	assert.Equal(t, branch.SpanContext.SpanID(), even.Parent.SpanID())
+}
+
+func Test_Unit_OtelWorkflow_RetryAndError(t *testing.T) {
+	// arrange
+	provider, exporter := newProvider()
+	actionErr := errors.New("test error")
+	action := workflow.Name("retry", workflow.Retry(
+		workflow.Name("attempt", workflow.Do(func(in int) (int, error) {
+			return 0, actionErr
+		})),
+		&workflow.RetryOptions{MaxRetries: 2},
+	))
+
+	// act
+	ctx := WithTracing(context.Background(), &Options{TracerProvider: provider})
+	_, err := action.Run(ctx, 1)
+
+	// assert
+	assert.ErrorIs(t, err, actionErr)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 4)
+
+	retry := findSpan(t, spans, "retry")
+	assert.Equal(t, "retry", attr(retry, StepKindKey).AsString())
+	assert.Equal(t, codes.Error, retry.Status.Code)
+	assert.Equal(t, "failed after 3 attempt(s): test error", retry.Status.Description)
+
+	var attempts []int64
+	for _, v := range spans {
+		if v.Name != "attempt" {
+			continue
+		}
+		assert.Equal(t, retry.SpanContext.SpanID(), v.Parent.SpanID())
+		assert.Equal(t, codes.Error, v.Status.Code)
+		attempts = append(attempts, attr(v, RetryAttemptKey).AsInt64())
+	}
+	assert.Equal(t, []int64{0, 1, 2}, attempts)
+}
+
+func Test_Unit_OtelWorkflow_UnnamedSteps(t *testing.T) {
+	// arrange
+	provider, exporter := newProvider()
+	action := workflow.Sequential(workflow.NoOp())
+
+	// act
+	_, err := action.Run(WithTracing(context.Background(), &Options{TracerProvider: provider}), nil)
+
+	// assert
+	assert.NoError(t, err)
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "noop", spans[0].Name)
+	assert.Equal(t, "sequential", spans[1].Name)
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+}
@@ -0,0 +1,258 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// A stage of a pipeline, which executes an action for each item.
+type Stage struct {
+	// Action executed for each item. Receives the output of the previous stage for the item.
+	Action Action
+
+	// Number of items the stage processes at the same time. Can set to 0 for 1.
+	Workers int
+
+	// What to do when the action returns an error for an item.
+	OnError ItemErrorPolicy
+}
+
+// Determines what a pipeline does when a stage fails for an item.
+type ItemErrorPolicy int
+
+const (
+	// Stops the pipeline and returns the error.
+	AbortOnError ItemErrorPolicy = iota
+
+	// Drops the item and continues with the next item.
+	SkipOnError
+
+	// Drops the item, passes it to PipelineOptions.DeadLetter and continues with the next item.
+	DeadLetterOnError
+)
+
+// Options for configuring a pipeline.
+type PipelineOptions struct {
+	// Capacity of the channels that connect the stages. A stage waits when the channel to the next stage is full, so a slow stage slows down the stages before it instead of items piling up. Can set to 0 for unbuffered channels.
+	BufferSize int
+
+	// Outputs items in the order they were input, instead of the order they completed. Items that complete early are held until every item before them has completed or been dropped.
+	PreserveOrder bool
+
+	// Optional function called with each item dropped by a stage with DeadLetterOnError. Called from the goroutine of the stage, so it must be safe for concurrent use.
+	DeadLetter func(item DeadLetter)
+}
+
+// An item that was dropped because a stage failed for it.
+type DeadLetter struct {
+	// Index of the stage that failed.
+	Stage int
+
+	// Position of the item in the input of the pipeline.
+	Index int
+
+	// Input passed to the stage.
+	In any
+
+	// Error returned by the stage.
+	Err error
+}
+
+// Processes a slice of items as a stream through stages connected by bounded channels. Each stage executes its action for one item at a time per worker and passes the output to the next stage, so every stage is working at the same time. The input must be a slice or an iter.Seq. Outputs a []any of the output of the last stage for every item that was not dropped.
+func Pipeline(opts *PipelineOptions, stages ...Stage) Action {
+	if opts == nil {
+		opts = &PipelineOptions{}
+	}
+
+	children := make([]Action, len(stages))
+	for i, v := range stages {
+		children[i] = v.Action
+	}
+
+	return newStep(KindPipeline, children, func(ctx context.Context, in any) (any, error) {
+		source, err := pipelineSource(in)
+		if err != nil {
+			return nil, err
+		}
+
+		outputs := []any{}
+		err = runPipeline(ctx, stages, opts, source, func(out any) bool {
+			outputs = append(outputs, out)
+			return true
+		})
+		return outputs, err
+	})
+}
+
+// Returns a function that yields each element of a slice or iter.Seq of any type.
+func pipelineSource(in any) (iter.Seq[any], error) {
+	switch in := in.(type) {
+	case nil:
+		return func(yield func(any) bool) {}, nil
+	case []any:
+		return slices.Values(in), nil
+	case iter.Seq[any]:
+		return in, nil
+	}
+
+	v := reflect.ValueOf(in)
+	switch {
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		return func(yield func(any) bool) {
+			for i := range v.Len() {
+				if !yield(v.Index(i).Interface()) {
+					return
+				}
+			}
+		}, nil
+	case isSeq(v.Type()):
+		return func(yield func(any) bool) {
+			v.Call([]reflect.Value{reflect.MakeFunc(v.Type().In(0), func(args []reflect.Value) []reflect.Value {
+				return []reflect.Value{reflect.ValueOf(yield(args[0].Interface()))}
+			})})
+		}, nil
+	default:
+		return nil, fmt.Errorf("pipeline input must be a slice or iter.Seq, got %T", in)
+	}
+}
+
+// Returns true if the type is an iter.Seq of any type.
+func isSeq(t reflect.Type) bool {
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		return false
+	}
+	yield := t.In(0)
+	return yield.Kind() == reflect.Func && yield.NumIn() == 1 && yield.NumOut() == 1 && yield.Out(0).Kind() == reflect.Bool
+}
+
+// An item passing through a pipeline.
+type pipelineItem struct {
+	index   int
+	value   any
+	dropped bool
+}
+
+// Streams every item from the source through the stages and passes the output of the last stage to the sink. Stops reading from the source when the sink returns false, a stage aborts or the context is cancelled, and cancels the context of any items that are being processed. Returns once every goroutine it started has finished.
+func runPipeline(parent context.Context, stages []Stage, opts *PipelineOptions, source iter.Seq[any], sink func(out any) bool) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	var errLock sync.Mutex
+	var firstErr error
+	abort := func(err error) {
+		errLock.Lock()
+		defer errLock.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	// send items from the source
+	items := make(chan pipelineItem, opts.BufferSize)
+	go func() {
+		defer close(items)
+		index := 0
+		source(func(value any) bool {
+			select {
+			case items <- pipelineItem{index: index, value: value}:
+				index++
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	// connect each stage to the next
+	var in <-chan pipelineItem = items
+	for i, stage := range stages {
+		out := make(chan pipelineItem, opts.BufferSize)
+		workers := max(stage.Workers, 1)
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for range workers {
+			go func(i int, stage Stage, in <-chan pipelineItem) {
+				defer wg.Done()
+				for item := range in {
+					if ctx.Err() != nil {
+						// drain the remaining items without processing them so every goroutine can finish
+						continue
+					}
+					if !item.dropped {
+						value, err := runChild(ctx, i, stage.Action, item.value)
+						if ctx.Err() != nil {
+							// the item was interrupted by the abort or cancellation, so its error is not its own
+							continue
+						}
+						if err != nil {
+							switch stage.OnError {
+							case SkipOnError:
+								item.dropped = true
+							case DeadLetterOnError:
+								if opts.DeadLetter != nil {
+									opts.DeadLetter(DeadLetter{Stage: i, Index: item.index, In: item.value, Err: err})
+								}
+								item.dropped = true
+							default:
+								abort(fmt.Errorf("item %d: %w", item.index, err))
+								continue
+							}
+						}
+						item.value = value
+					}
+
+					select {
+					case out <- item:
+					case <-ctx.Done():
+					}
+				}
+			}(i, stage, in)
+		}
+		go func() {
+			wg.Wait()
+			close(out)
+		}()
+		in = out
+	}
+
+	// pass outputs to the sink, holding items that complete early if the order is preserved
+	pending := map[int]pipelineItem{}
+	next := 0
+	emit := func(item pipelineItem) {
+		if !item.dropped && ctx.Err() == nil && !sink(item.value) {
+			cancel()
+		}
+	}
+	for item := range in {
+		if ctx.Err() != nil {
+			// drain the remaining items so every goroutine can finish
+			continue
+		}
+		if !opts.PreserveOrder {
+			emit(item)
+			continue
+		}
+		pending[item.index] = item
+		for {
+			item, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			emit(item)
+		}
+	}
+
+	errLock.Lock()
+	defer errLock.Unlock()
+	if firstErr != nil {
+		return firstErr
+	}
+	return parent.Err()
+}
@@ -0,0 +1,249 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Pipeline_PreserveOrder(t *testing.T) {
+	// arrange
+	action := Sequential(
+		Do(func(in int) ([]int, error) {
+			return []int{1, 2, 3, 4, 5, 6}, nil
+		}),
+		Pipeline(&PipelineOptions{PreserveOrder: true, BufferSize: 2},
+			Stage{Action: Do(func(in int) (int, error) {
+				// later items finish first
+				time.Sleep(time.Duration(6-in) * time.Millisecond)
+				return in * 10, nil
+			}), Workers: 6},
+			Stage{Action: Do(func(in int) (int, error) {
+				return in + 1, nil
+			}), Workers: 2},
+		),
+	)
+
+	// act
+	out, err := action.Run(context.Background(), 0)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []any{11, 21, 31, 41, 51, 61}, out)
+}
+
+func Test_Unit_Pipeline_CompletionOrder(t *testing.T) {
+	// arrange
+	action := Pipeline(nil, Stage{Action: Do(func(in int) (int, error) {
+		return in * 2, nil
+	}), Workers: 3})
+
+	// act
+	out, err := action.Run(context.Background(), []int{1, 2, 3, 4})
+
+	// assert
+	assert.NoError(t, err)
+	outputs := []int{}
+	for _, v := range out.([]any) {
+		outputs = append(outputs, v.(int))
+	}
+	sort.Ints(outputs)
+	assert.Equal(t, []int{2, 4, 6, 8}, outputs)
+}
+
+func Test_Unit_Pipeline_ErrorPolicies(t *testing.T) {
+	// arrange
+	stageErr := errors.New("test error")
+	failOn := func(fail int) Action {
+		return Do(func(in int) (int, error) {
+			if in == fail {
+				return 0, stageErr
+			}
+			return in, nil
+		})
+	}
+	var lock sync.Mutex
+	var deadLetters []DeadLetter
+	action := Pipeline(&PipelineOptions{
+		PreserveOrder: true,
+		DeadLetter: func(item DeadLetter) {
+			lock.Lock()
+			defer lock.Unlock()
+			deadLetters = append(deadLetters, item)
+		},
+	},
+		Stage{Action: failOn(2), OnError: SkipOnError},
+		Stage{Action: failOn(4), OnError: DeadLetterOnError, Workers: 2},
+	)
+
+	// act
+	out, err := action.Run(context.Background(), []int{1, 2, 3, 4, 5})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []any{1, 3, 5}, out)
+	assert.Equal(t, []DeadLetter{{Stage: 1, Index: 3, In: 4, Err: stageErr}}, deadLetters)
+}
+
+func Test_Unit_Pipeline_Abort(t *testing.T) {
+	// arrange
+	stageErr := errors.New("test error")
+	var cancelled atomic.Int32
+	started := make(chan struct{}, 2)
+	action := Pipeline(nil,
+		Stage{Action: ActionFunc(func(ctx context.Context, in any) (any, error) {
+			if in.(int) == 0 {
+				// fail once the items before it are being processed
+				<-started
+				<-started
+				return nil, stageErr
+			}
+			started <- struct{}{}
+			select {
+			case <-ctx.Done():
+				cancelled.Add(1)
+				return nil, ctx.Err()
+			case <-time.After(time.Second * 5):
+				return in, nil
+			}
+		}), Workers: 3},
+	)
+
+	// act
+	start := time.Now()
+	_, err := action.Run(context.Background(), []int{1, 2, 0, 3, 4})
+
+	// assert
+	assert.ErrorIs(t, err, stageErr)
+	assert.Equal(t, "item 2: test error", err.Error())
+	assert.Equal(t, int32(2), cancelled.Load())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func Test_Unit_Pipeline_NoWorkAfterAbort(t *testing.T) {
+	// arrange
+	stageErr := errors.New("test error")
+	var calls atomic.Int32
+	action := Pipeline(&PipelineOptions{BufferSize: 10},
+		Stage{Action: Do(func(in int) (int, error) {
+			calls.Add(1)
+			if in == 5 {
+				return 0, stageErr
+			}
+			return in, nil
+		})},
+	)
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+
+	// act
+	_, err := action.Run(context.Background(), items)
+
+	// assert
+	assert.ErrorIs(t, err, stageErr)
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+func Test_Unit_Pipeline_NoDeadLetterAfterAbort(t *testing.T) {
+	// arrange
+	stageErr := errors.New("test error")
+	var lock sync.Mutex
+	var deadLetters []DeadLetter
+	action := Pipeline(&PipelineOptions{
+		BufferSize: 10,
+		DeadLetter: func(item DeadLetter) {
+			lock.Lock()
+			defer lock.Unlock()
+			deadLetters = append(deadLetters, item)
+		},
+	},
+		Stage{Action: ActionFunc(func(ctx context.Context, in any) (any, error) {
+			if in.(int) == 0 {
+				return in, nil
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}), Workers: 3, OnError: DeadLetterOnError},
+		Stage{Action: Do(func(in int) (int, error) {
+			return 0, stageErr
+		})},
+	)
+
+	// act
+	_, err := action.Run(context.Background(), []int{0, 1, 2, 3, 4})
+
+	// assert
+	assert.ErrorIs(t, err, stageErr)
+	assert.Empty(t, deadLetters)
+}
+
+func Test_Unit_Pipeline_Backpressure(t *testing.T) {
+	// arrange
+	var produced atomic.Int32
+	release := make(chan struct{})
+	action := Pipeline(nil,
+		Stage{Action: Do(func(in int) (int, error) {
+			produced.Add(1)
+			return in, nil
+		})},
+		Stage{Action: Do(func(in int) (int, error) {
+			<-release
+			return in, nil
+		})},
+	)
+
+	// act
+	done := make(chan error)
+	go func() {
+		_, err := action.Run(context.Background(), make([]int, 100))
+		done <- err
+	}()
+	time.Sleep(time.Millisecond * 50)
+	blocked := produced.Load()
+	close(release)
+
+	// assert
+	assert.NoError(t, <-done)
+	assert.LessOrEqual(t, blocked, int32(3))
+	assert.Equal(t, int32(100), produced.Load())
+}
+
+func Test_Unit_Pipeline_InvalidInput(t *testing.T) {
+	// act
+	_, err := Pipeline(nil, Stage{Action: NoOp()}).Run(context.Background(), 1)
+
+	// assert
+	assert.EqualError(t, err, "pipeline input must be a slice or iter.Seq, got int")
+}
+
+func Test_Unit_Pipeline_Hooks(t *testing.T) {
+	// arrange
+	var lock sync.Mutex
+	var paths []string
+	ctx := WithHooks(context.Background(), &Hooks{
+		OnStepStart: func(ctx context.Context, step StepInfo, in any) context.Context {
+			lock.Lock()
+			defer lock.Unlock()
+			paths = append(paths, step.Path)
+			return ctx
+		},
+	})
+	action := Name("stream", Pipeline(nil, Stage{Action: Name("double", Do(func(in int) (int, error) {
+		return in * 2, nil
+	}))}))
+
+	// act
+	_, err := action.Run(ctx, []int{1, 2})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"stream", "stream/double[0]", "stream/double[0]"}, paths)
+}
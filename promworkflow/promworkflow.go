@@ -0,0 +1,234 @@
+// Package promworkflow records workflow metrics in memory and renders them in the Prometheus text exposition format.
+package promworkflow
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	workflow "github.com/eleniums/go-workflow"
+)
+
+// Content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Default histogram buckets in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Options for configuring a registry.
+type Options struct {
+	// Prefix added to the name of every metric. Can set to empty to use "workflow".
+	Namespace string
+
+	// Upper bounds in seconds of the step latency histogram buckets. Can set to nil to use DefaultBuckets.
+	LatencyBuckets []float64
+
+	// Upper bounds in seconds of the retry delay histogram buckets. Can set to nil to use DefaultBuckets.
+	RetryDelayBuckets []float64
+}
+
+// In-process implementation of workflow.Metrics. Metrics are labeled by step name and kind.
+type Registry struct {
+	namespace         string
+	latencyBuckets    []float64
+	retryDelayBuckets []float64
+
+	lock          sync.Mutex
+	invocations   map[labels]uint64
+	errors        map[labels]uint64
+	retries       map[labels]uint64
+	retriesDenied map[labels]uint64
+	latency       map[labels]*histogram
+	retryDelay    map[labels]*histogram
+}
+
+// Labels attached to every metric.
+type labels struct {
+	step string
+	kind string
+}
+
+// Cumulative histogram of observed values.
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+var _ workflow.Metrics = (*Registry)(nil)
+var _ http.Handler = (*Registry)(nil)
+
+// Creates an empty registry.
+func NewRegistry(opts *Options) *Registry {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	r := &Registry{
+		namespace:         opts.Namespace,
+		latencyBuckets:    opts.LatencyBuckets,
+		retryDelayBuckets: opts.RetryDelayBuckets,
+		invocations:       map[labels]uint64{},
+		errors:            map[labels]uint64{},
+		retries:           map[labels]uint64{},
+		retriesDenied:     map[labels]uint64{},
+		latency:           map[labels]*histogram{},
+		retryDelay:        map[labels]*histogram{},
+	}
+	if r.namespace == "" {
+		r.namespace = "workflow"
+	}
+	if r.latencyBuckets == nil {
+		r.latencyBuckets = DefaultBuckets
+	}
+	if r.retryDelayBuckets == nil {
+		r.retryDelayBuckets = DefaultBuckets
+	}
+
+	return r
+}
+
+// Counts a step invocation.
+func (r *Registry) StepStarted(step workflow.StepInfo) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.invocations[labelsFor(step)]++
+}
+
+// Records step latency and counts an error if one occurred.
+func (r *Registry) StepEnded(step workflow.StepInfo, elapsed time.Duration, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	l := labelsFor(step)
+	observe(r.latency, l, r.latencyBuckets, elapsed)
+	if err != nil {
+		r.errors[l]++
+	}
+}
+
+// Counts a retry and records the retry delay.
+func (r *Registry) RetryScheduled(step workflow.StepInfo, delay time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	l := labelsFor(step)
+	r.retries[l]++
+	observe(r.retryDelay, l, r.retryDelayBuckets, delay)
+}
+
+// Counts a retry denied by a retry budget.
+func (r *Registry) RetryDenied(step workflow.StepInfo) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.retriesDenied[labelsFor(step)]++
+}
+
+// Renders all metrics in the Prometheus text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.Write(w)
+}
+
+// Writes all metrics in the Prometheus text exposition format.
+func (r *Registry) Write(w io.Writer) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	buf := bufio.NewWriter(w)
+	writeCounter(buf, r.namespace+"_step_invocations_total", "Number of times a step was invoked.", r.invocations)
+	writeCounter(buf, r.namespace+"_step_errors_total", "Number of times a step returned an error.", r.errors)
+	writeCounter(buf, r.namespace+"_retries_total", "Number of retries scheduled by a retry step.", r.retries)
+	writeCounter(buf, r.namespace+"_retries_denied_total", "Number of retries denied because the retry budget was exhausted.", r.retriesDenied)
+	writeHistogram(buf, r.namespace+"_step_duration_seconds", "Time taken for a step to complete.", r.latencyBuckets, r.latency)
+	writeHistogram(buf, r.namespace+"_retry_delay_seconds", "Delay before a retry, including jitter.", r.retryDelayBuckets, r.retryDelay)
+	return buf.Flush()
+}
+
+// Returns the labels for a step.
+func labelsFor(step workflow.StepInfo) labels {
+	return labels{
+		step: step.Name,
+		kind: string(step.Kind),
+	}
+}
+
+// Adds a value to the histogram with the given labels, creating it if necessary.
+func observe(histograms map[labels]*histogram, l labels, buckets []float64, d time.Duration) {
+	h, ok := histograms[l]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(buckets))}
+		histograms[l] = h
+	}
+
+	v := d.Seconds()
+	for i, bound := range buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// Writes a counter metric family.
+func writeCounter(w io.Writer, name string, help string, values map[labels]uint64) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, l := range sortedLabels(values) {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, l.String(), values[l])
+	}
+}
+
+// Writes a histogram metric family.
+func writeHistogram(w io.Writer, name string, help string, buckets []float64, values map[labels]*histogram) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, l := range sortedLabels(values) {
+		h := values[l]
+		for i, bound := range buckets {
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, l.String(), formatFloat(bound), h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l.String(), h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, l.String(), formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, l.String(), h.count)
+	}
+}
+
+// Returns the keys of a map sorted by step name and then kind.
+func sortedLabels[T any](values map[labels]T) []labels {
+	keys := make([]labels, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].step != keys[j].step {
+			return keys[i].step < keys[j].step
+		}
+		return keys[i].kind < keys[j].kind
+	})
+	return keys
+}
+
+// Formats labels as they appear between braces.
+func (l labels) String() string {
+	return fmt.Sprintf("kind=\"%s\",step=\"%s\"", escape(l.kind), escape(l.step))
+}
+
+// Escapes a label value.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// Formats a float the way Prometheus expects.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
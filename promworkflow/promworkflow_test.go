@@ -0,0 +1,98 @@
+package promworkflow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+
+	workflow "github.com/eleniums/go-workflow"
+)
+
+func Test_Unit_PromWorkflow_Write(t *testing.T) {
+	// arrange
+	registry := NewRegistry(&Options{
+		LatencyBuckets:    []float64{1},
+		RetryDelayBuckets: []float64{0.1, 1},
+	})
+	step := workflow.StepInfo{Name: `say "hi"`, Kind: workflow.KindAction}
+	retry := workflow.StepInfo{Kind: workflow.KindRetry}
+
+	// act
+	registry.StepStarted(step)
+	registry.StepEnded(step, time.Millisecond*500, nil)
+	registry.StepStarted(step)
+	registry.StepEnded(step, time.Second*2, errors.New("test error"))
+	registry.RetryScheduled(retry, time.Millisecond*500)
+	registry.RetryDenied(retry)
+
+	var buf strings.Builder
+	err := registry.Write(&buf)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, `# HELP workflow_step_invocations_total Number of times a step was invoked.
+# TYPE workflow_step_invocations_total counter
+workflow_step_invocations_total{kind="action",step="say \"hi\""} 2
+# HELP workflow_step_errors_total Number of times a step returned an error.
+# TYPE workflow_step_errors_total counter
+workflow_step_errors_total{kind="action",step="say \"hi\""} 1
+# HELP workflow_retries_total Number of retries scheduled by a retry step.
+# TYPE workflow_retries_total counter
+workflow_retries_total{kind="retry",step=""} 1
+# HELP workflow_retries_denied_total Number of retries denied because the retry budget was exhausted.
+# TYPE workflow_retries_denied_total counter
+workflow_retries_denied_total{kind="retry",step=""} 1
+# HELP workflow_step_duration_seconds Time taken for a step to complete.
+# TYPE workflow_step_duration_seconds histogram
+workflow_step_duration_seconds_bucket{kind="action",step="say \"hi\"",le="1"} 1
+workflow_step_duration_seconds_bucket{kind="action",step="say \"hi\"",le="+Inf"} 2
+workflow_step_duration_seconds_sum{kind="action",step="say \"hi\""} 2.5
+workflow_step_duration_seconds_count{kind="action",step="say \"hi\""} 2
+# HELP workflow_retry_delay_seconds Delay before a retry, including jitter.
+# TYPE workflow_retry_delay_seconds histogram
+workflow_retry_delay_seconds_bucket{kind="retry",step="",le="0.1"} 0
+workflow_retry_delay_seconds_bucket{kind="retry",step="",le="1"} 1
+workflow_retry_delay_seconds_bucket{kind="retry",step="",le="+Inf"} 1
+workflow_retry_delay_seconds_sum{kind="retry",step=""} 0.5
+workflow_retry_delay_seconds_count{kind="retry",step=""} 1
+`, buf.String())
+}
+
+func Test_Unit_PromWorkflow_ServeHTTP(t *testing.T) {
+	// arrange
+	registry := NewRegistry(&Options{Namespace: "test"})
+	actionErr := errors.New("test error")
+	action := workflow.Name("flaky", workflow.Retry(workflow.Name("call", workflow.Do(func(in int) (int, error) {
+		return 0, actionErr
+	})), &workflow.RetryOptions{MaxRetries: 2}))
+
+	_, err := action.Run(workflow.WithMetrics(context.Background(), registry), 1)
+	assert.ErrorIs(t, err, actionErr)
+
+	server := httptest.NewServer(registry)
+	defer server.Close()
+
+	// act
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, ContentType, resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), `test_step_invocations_total{kind="action",step="call"} 3`)
+	assert.Contains(t, string(body), `test_step_invocations_total{kind="retry",step="flaky"} 1`)
+	assert.Contains(t, string(body), `test_step_errors_total{kind="action",step="call"} 3`)
+	assert.Contains(t, string(body), `test_step_errors_total{kind="retry",step="flaky"} 1`)
+	assert.Contains(t, string(body), `test_retries_total{kind="retry",step="flaky"} 2`)
+	assert.Contains(t, string(body), `test_retry_delay_seconds_count{kind="retry",step="flaky"} 2`)
+	assert.Contains(t, string(body), `test_step_duration_seconds_count{kind="action",step="call"} 3`)
+}
@@ -0,0 +1,330 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// Every step of a run, recorded so the run can be replayed later without calling the real actions.
+type Recording struct {
+	// Input the workflow was run with.
+	Input *RecordedValue `json:"input"`
+
+	// Output returned by the workflow.
+	Output *RecordedValue `json:"output"`
+
+	// Error returned by the workflow, if any.
+	Error string `json:"error,omitempty"`
+
+	// Every action step that was executed, in the order they completed.
+	Steps []*RecordedStep `json:"steps"`
+
+	// Order the actions of each parallel step completed in.
+	Parallel []*RecordedOrder `json:"parallel,omitempty"`
+
+	lock     sync.Mutex
+	replayed map[string]int
+}
+
+// An action step that was executed during a recorded run.
+type RecordedStep struct {
+	Path   string         `json:"path"`
+	Input  *RecordedValue `json:"input"`
+	Output *RecordedValue `json:"output"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// Order the actions of a parallel step completed in, by index.
+type RecordedOrder struct {
+	Path  string `json:"path"`
+	Order []int  `json:"order"`
+}
+
+// A value serialized as JSON along with its Go type, so it can be decoded as the same type when replayed.
+type RecordedValue struct {
+	// Go type of the value, i.e. "int" or "*workflow.ExecResult". Empty for nil.
+	Type string `json:"type,omitempty"`
+
+	// Value serialized as JSON.
+	Value json.RawMessage `json:"value,omitempty"`
+
+	// Error returned when serializing the value, if any. A value that could not be serialized cannot be replayed.
+	Error string `json:"error,omitempty"`
+}
+
+// Decoders for the types that can be replayed, by type name.
+var (
+	recordTypesLock sync.RWMutex
+	recordTypes     = map[string]func(data []byte) (any, error){}
+)
+
+func init() {
+	RegisterRecordType[string]()
+	RegisterRecordType[bool]()
+	RegisterRecordType[int]()
+	RegisterRecordType[int8]()
+	RegisterRecordType[int16]()
+	RegisterRecordType[int32]()
+	RegisterRecordType[int64]()
+	RegisterRecordType[uint]()
+	RegisterRecordType[uint8]()
+	RegisterRecordType[uint16]()
+	RegisterRecordType[uint32]()
+	RegisterRecordType[uint64]()
+	RegisterRecordType[float32]()
+	RegisterRecordType[float64]()
+	RegisterRecordType[[]byte]()
+	RegisterRecordType[[]string]()
+	RegisterRecordType[[]any]()
+	RegisterRecordType[map[string]any]()
+	RegisterRecordType[*ExecResult]()
+}
+
+// Registers a type so values of the type can be replayed. Built-in types are already registered.
+func RegisterRecordType[T any]() {
+	recordTypesLock.Lock()
+	defer recordTypesLock.Unlock()
+	recordTypes[reflect.TypeOf((*T)(nil)).Elem().String()] = func(data []byte) (any, error) {
+		var v T
+		err := json.Unmarshal(data, &v)
+		return v, err
+	}
+}
+
+// Returns a copy of the context that records every step executed with it to the recording. Only steps created by this package are recorded, so hand-written actions should be given a name with Name.
+func WithRecorder(ctx context.Context, recording *Recording) context.Context {
+	ctx = context.WithValue(ctx, replayKey{}, &replaySession{recording: recording, record: true})
+	return WithHooks(ctx, &Hooks{
+		OnStepStart: func(ctx context.Context, step StepInfo, in any) context.Context {
+			// the context holds the step that contains this step, so there is none for the outermost step
+			if stepInfoFromContext(ctx).Path == "" {
+				recording.lock.Lock()
+				recording.Input = recordValue(in)
+				recording.lock.Unlock()
+			}
+			return ctx
+		},
+		OnStepEnd: func(ctx context.Context, step StepInfo, out any, err error) {
+			if stepInfoFromContext(ctx).Path == "" {
+				recording.lock.Lock()
+				recording.Output = recordValue(out)
+				recording.Error = errorString(err)
+				recording.lock.Unlock()
+			}
+		},
+		Intercept: func(ctx context.Context, step StepInfo, in any, next Action) (any, error) {
+			out, err := next.Run(ctx, in)
+			if step.Kind == KindAction {
+				recording.lock.Lock()
+				recording.Steps = append(recording.Steps, &RecordedStep{
+					Path:   step.Path,
+					Input:  recordValue(in),
+					Output: recordValue(out),
+					Error:  errorString(err),
+				})
+				recording.lock.Unlock()
+			}
+			return out, err
+		},
+	})
+}
+
+// Returns a copy of the context that replays the recording. Action steps are not executed; the recorded output and error are returned instead, in the order they were recorded for each step, and parallel steps complete in the recorded order. Everything else, such as conditions and reducers, is executed as usual. Recorded errors are replayed as errors with the same message.
+func WithReplay(ctx context.Context, recording *Recording) context.Context {
+	recording.lock.Lock()
+	recording.replayed = nil
+	recording.lock.Unlock()
+
+	ctx = context.WithValue(ctx, replayKey{}, &replaySession{recording: recording})
+	return WithHooks(ctx, &Hooks{
+		Intercept: func(ctx context.Context, step StepInfo, in any, next Action) (any, error) {
+			if step.Kind != KindAction {
+				return next.Run(ctx, in)
+			}
+			return recording.replayStep(step.Path)
+		},
+	})
+}
+
+// Writes the recording to a file as JSON.
+func (r *Recording) WriteFile(path string) error {
+	r.lock.Lock()
+	data, err := json.MarshalIndent(r, "", "  ")
+	r.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Reads a recording from a file written by WriteFile.
+func LoadRecording(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	recording := &Recording{}
+	if err := json.Unmarshal(data, recording); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return recording, nil
+}
+
+// Decodes the recorded input of the run.
+func (r *Recording) In() (any, error) {
+	return r.Input.Decode()
+}
+
+// Decodes the recorded output and error of the run.
+func (r *Recording) Out() (any, error) {
+	out, err := r.Output.Decode()
+	if err != nil {
+		return nil, err
+	}
+	if r.Error != "" {
+		return out, errors.New(r.Error)
+	}
+	return out, nil
+}
+
+// Returns the next recorded result of an action step.
+func (r *Recording) replayStep(path string) (any, error) {
+	r.lock.Lock()
+	if r.replayed == nil {
+		r.replayed = map[string]int{}
+	}
+	var found *RecordedStep
+	skip := r.replayed[path]
+	for _, v := range r.Steps {
+		if v.Path != path {
+			continue
+		}
+		if skip == 0 {
+			found = v
+			break
+		}
+		skip--
+	}
+	r.replayed[path]++
+	r.lock.Unlock()
+
+	if found == nil {
+		return nil, fmt.Errorf("replay: no recorded result for step %s", path)
+	}
+	out, err := found.Output.Decode()
+	if err != nil {
+		return nil, err
+	}
+	if found.Error != "" {
+		return out, errors.New(found.Error)
+	}
+	return out, nil
+}
+
+// Decodes the value as the type it was recorded with.
+func (v *RecordedValue) Decode() (any, error) {
+	if v == nil || v.Type == "" {
+		return nil, nil
+	}
+	if v.Error != "" {
+		return nil, fmt.Errorf("replay: value of type %s was not recorded: %s", v.Type, v.Error)
+	}
+
+	recordTypesLock.RLock()
+	decode, ok := recordTypes[v.Type]
+	recordTypesLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("replay: type %s is not registered, register it with RegisterRecordType", v.Type)
+	}
+	return decode(v.Value)
+}
+
+type replayKey struct{}
+
+// A recording that is being recorded or replayed.
+type replaySession struct {
+	recording *Recording
+	record    bool
+}
+
+// Returns the order a parallel step completed in. When recording, the order is recorded. When replaying, the recorded order is returned instead.
+func parallelOrder(ctx context.Context, order []int) []int {
+	session, _ := ctx.Value(replayKey{}).(*replaySession)
+	if session == nil {
+		return order
+	}
+
+	path := stepInfoFromContext(ctx).Path
+	r := session.recording
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if session.record {
+		r.Parallel = append(r.Parallel, &RecordedOrder{Path: path, Order: order})
+		return order
+	}
+
+	if r.replayed == nil {
+		r.replayed = map[string]int{}
+	}
+	key := "parallel:" + path
+	skip := r.replayed[key]
+	r.replayed[key]++
+	for _, v := range r.Parallel {
+		if v.Path != path {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		if isPermutation(v.Order, len(order)) {
+			return v.Order
+		}
+		break
+	}
+	return order
+}
+
+// Returns true if order contains each index from 0 to n-1 exactly once.
+func isPermutation(order []int, n int) bool {
+	if len(order) != n {
+		return false
+	}
+	seen := make([]bool, n)
+	for _, v := range order {
+		if v < 0 || v >= n || seen[v] {
+			return false
+		}
+		seen[v] = true
+	}
+	return true
+}
+
+// Serializes a value for a recording.
+func recordValue(v any) *RecordedValue {
+	if v == nil {
+		return &RecordedValue{}
+	}
+	recorded := &RecordedValue{Type: reflect.TypeOf(v).String()}
+	data, err := json.Marshal(v)
+	if err != nil {
+		recorded.Error = err.Error()
+		return recorded
+	}
+	recorded.Value = data
+	return recorded
+}
+
+// Returns the message of an error, or empty if there is no error.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,195 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+type testReceipt struct {
+	Items []string
+	Total int
+}
+
+func Test_Unit_Replay_RecordAndReplay(t *testing.T) {
+	// arrange
+	RegisterRecordType[testReceipt]()
+
+	live := true
+	var calls atomic.Int32
+	numErrs := 1
+	step := func(name string, fn func(in any) (any, error)) Action {
+		return Name(name, ActionFunc(func(ctx context.Context, in any) (any, error) {
+			assert.True(t, live, "%s should not be executed when replaying", name)
+			calls.Add(1)
+			return fn(in)
+		}))
+	}
+	concat := func(in []Result) (testReceipt, error) {
+		receipt := testReceipt{}
+		for _, v := range in {
+			if v.Err != nil {
+				return receipt, v.Err
+			}
+			receipt.Items = append(receipt.Items, v.Out.(string))
+		}
+		receipt.Total = len(receipt.Items)
+		return receipt, nil
+	}
+	action := Name("root", Sequential(
+		step("fetch", func(in any) (any, error) {
+			return in.(int) * 2, nil
+		}),
+		Parallel(concat,
+			step("slow", func(in any) (any, error) {
+				time.Sleep(time.Millisecond * 20)
+				return "slow", nil
+			}),
+			step("fast", func(in any) (any, error) {
+				return "fast", nil
+			}),
+		),
+		If(func(in testReceipt) (bool, error) {
+			return in.Total == 2, nil
+		},
+			Retry(step("flaky", func(in any) (any, error) {
+				if numErrs > 0 {
+					numErrs--
+					return nil, errors.New("test error")
+				}
+				return in, nil
+			}), &RetryOptions{MaxRetries: 1}),
+			NoOp(),
+		),
+	))
+
+	recording := &Recording{}
+
+	// act
+	out, err := action.Run(WithRecorder(context.Background(), recording), 21)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, testReceipt{Items: []string{"fast", "slow"}, Total: 2}, out)
+	assert.Equal(t, int32(5), calls.Load())
+
+	var paths []string
+	for _, v := range recording.Steps {
+		paths = append(paths, v.Path+" "+v.Error)
+	}
+	assert.Equal(t, []string{
+		"root/fetch[0] ",
+		"root/parallel[1]/fast[1] ",
+		"root/parallel[1]/slow[0] ",
+		"root/if[2]/retry[0]/flaky[0] test error",
+		"root/if[2]/retry[0]/flaky[0] ",
+	}, paths)
+	assert.Equal(t, []*RecordedOrder{{Path: "root/parallel[1]", Order: []int{1, 0}}}, recording.Parallel)
+
+	// act
+	path := filepath.Join(t.TempDir(), "recording.json")
+	assert.NoError(t, recording.WriteFile(path))
+	loaded, err := LoadRecording(path)
+	assert.NoError(t, err)
+
+	in, err := loaded.In()
+	assert.NoError(t, err)
+	expected, expectedErr := loaded.Out()
+
+	// replay twice to check the recording can be reused
+	for i := 0; i < 2; i++ {
+		live = false
+		out, err = action.Run(WithReplay(context.Background(), loaded), in)
+
+		// assert
+		assert.Equal(t, 21, in)
+		assert.NoError(t, expectedErr)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, out)
+		assert.Equal(t, int32(5), calls.Load())
+	}
+}
+
+func Test_Unit_Replay_Errors(t *testing.T) {
+	// arrange
+	recording := &Recording{}
+	action := Do(func(in int) (int, error) {
+		return 0, errors.New("test error")
+	})
+
+	// act
+	_, err := action.Run(WithRecorder(context.Background(), recording), 1)
+
+	// assert
+	assert.EqualError(t, err, "test error")
+	out, err := recording.Out()
+	assert.Equal(t, 0, out)
+	assert.EqualError(t, err, "test error")
+
+	// act
+	out, err = action.Run(WithReplay(context.Background(), recording), 1)
+
+	// assert
+	assert.Equal(t, 0, out)
+	assert.EqualError(t, err, "test error")
+
+	// act
+	_, err = Sequential(NoOp(), action).Run(WithReplay(context.Background(), recording), 1)
+
+	// assert
+	assert.EqualError(t, err, "replay: no recorded result for step sequential/action[1]")
+}
+
+func Test_Unit_Replay_Values(t *testing.T) {
+	type unregistered struct {
+		Value int
+	}
+
+	testCases := []struct {
+		name     string
+		value    any
+		expected any
+		err      string
+	}{
+		{name: "nil", value: nil, expected: nil},
+		{name: "string", value: "text", expected: "text"},
+		{name: "map", value: map[string]any{"a": 1.5}, expected: map[string]any{"a": 1.5}},
+		{name: "exec result", value: &ExecResult{Stdout: "out", ExitCode: 1}, expected: &ExecResult{Stdout: "out", ExitCode: 1}},
+		{name: "unregistered", value: unregistered{Value: 1}, err: "replay: type workflow.unregistered is not registered, register it with RegisterRecordType"},
+		{name: "unserializable", value: make(chan int), err: "replay: value of type chan int was not recorded: json: unsupported type: chan int"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// act
+			out, err := recordValue(tc.value).Decode()
+
+			// assert
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+}
+
+func Test_Unit_Replay_LoadRecordingInvalid(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "recording.json")
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	// act
+	_, err := LoadRecording(path)
+
+	// assert
+	assert.True(t, strings.HasPrefix(err.Error(), path+": "))
+}
@@ -1,7 +1,12 @@
 package workflow
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
 	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -16,17 +21,37 @@ type RetryOptions struct {
 	// Maximum possible delay between retries. Can set to 0 for no maximum delay.
 	MaxDelay time.Duration
 
+	// Maximum time to spend retrying, measured from the start of the initial try. Retrying stops once the delay before the next retry would go past it. Can set to 0 for no limit.
+	MaxElapsedTime time.Duration
+
+	// Stops retrying once the delay before the next retry would go past the deadline of the context, if it has one.
+	StopAtDeadline bool
+
+	// Optional budget shared with other retry steps that limits how many retries can happen across all of them. When the budget is exhausted, the error is returned without retrying. Can set to nil for no shared limit.
+	Budget *RetryBudget
+
 	// Defines the maximum range of randomness to add to the delay between retries, i.e. jitter of 200 ms with a delay of 500 ms means the range for the actual delay is 300 ms to 700 ms. This helps prevent the thundering herd issue that can happen when a large number of concurrent transactions retry at the exact same time. Can set to 0 for no jitter.
 	Jitter time.Duration
 
 	// Optional function to determine if a retry should happen. This function is always called, even if no error has occurred.
 	ShouldRetry func(out any, err error) bool
 
-	// Optional function to determine backoff strategy. Can set to nil for no backoff.
-	BackoffStrategy func(delay time.Duration) time.Duration
+	// Optional function called after an attempt fails and another attempt has been scheduled, with the attempt that will be made next, the delay before it and the result of the failed attempt.
+	OnRetry func(attempt int, delay time.Duration, out any, err error)
+
+	// Optional function to determine backoff strategy, which returns the delay before each retry. The delay is then limited to MaxDelay and jitter is added. Can set to nil for no backoff.
+	BackoffStrategy func(b Backoff) time.Duration
+
+	// Optional clock used to wait between retries. Can set to nil to use the clock attached to the context with WithClock, or the system clock if there is none.
+	Clock Clock
+
+	// Optional source of randomness for jitter, i.e. rand.New(rand.NewSource(seed)) for repeatable delays. Can be shared by retries that execute concurrently. Can set to nil to use the global source.
+	Rand *rand.Rand
 }
 
 // Retry an action if it returns an error.
+//
+// If the error, or any error it wraps, has a method "RetryAfter() time.Duration" that returns a positive duration, that delay is used instead of the backoff strategy, limited to MaxDelay. If the error was wrapped with Permanent, the action is not retried.
 func Retry(action Action, opts *RetryOptions) Action {
 	if opts == nil {
 		// set some defaults if no options provided
@@ -39,65 +64,316 @@ func Retry(action Action, opts *RetryOptions) Action {
 		}
 	}
 
-	return func(in any) (any, error) {
+	s := newStep(KindRetry, []Action{action}, func(ctx context.Context, in any) (any, error) {
 		delay := opts.InitialDelay
+		clock := opts.Clock
+		if clock == nil {
+			clock = clockFromContext(ctx)
+		}
+		started := clock.Now()
+		if opts.Budget != nil {
+			opts.Budget.request()
+		}
 
 		// first loop is the initial try and does not count as a retry
+		var attempts []RetryAttempt
+		var out any
 		for retry := 0; retry <= opts.MaxRetries; retry++ {
-			out, err := action(in)
+			// stop retrying once the workflow has been cancelled
+			if ctx.Err() != nil {
+				return out, &RetryError{Attempts: attempts, Cause: ctx.Err()}
+			}
+
+			start := clock.Now()
+			var err error
+			out, err = runChild(withAttempt(ctx, retry), 0, action, in)
+			if err != nil {
+				attempts = append(attempts, RetryAttempt{Attempt: retry, Start: start, End: clock.Now(), Err: err})
+			}
+
 			if err != nil && retry >= opts.MaxRetries {
 				// already retried the maximum number of times, return error
-				return out, err
+				return out, &RetryError{Attempts: attempts}
 			} else if err == nil {
 				// action was successful, return immediately
 				return out, nil
+			} else if isPermanent(err) {
+				// error can never succeed on retry
+				return out, &RetryError{Attempts: attempts}
 			} else if opts.ShouldRetry != nil && !opts.ShouldRetry(out, err) {
 				// determined no retry should be allowed
-				return out, err
+				return out, &RetryError{Attempts: attempts}
 			}
 
-			// delay before next retry
-			time.Sleep(randDuration(delay-opts.Jitter, delay+opts.Jitter))
-
-			// increase delay as required by the backoff strategy
+			// delay before next retry, as determined by the backoff strategy
 			if opts.BackoffStrategy != nil {
-				delay = opts.BackoffStrategy(delay)
+				delay = opts.BackoffStrategy(Backoff{
+					Attempt:      retry + 1,
+					InitialDelay: opts.InitialDelay,
+					MaxDelay:     opts.MaxDelay,
+					Delay:        delay,
+					Rand: func(min time.Duration, max time.Duration) time.Duration {
+						return randDuration(opts.Rand, min, max)
+					},
+				})
 			}
 
 			// respect max delay if set
 			if opts.MaxDelay > 0 && delay > opts.MaxDelay {
 				delay = opts.MaxDelay
 			}
+
+			wait := randDuration(opts.Rand, max(delay-opts.Jitter, 0), delay+opts.Jitter)
+
+			// the error knows how long to wait, i.e. from a Retry-After header
+			if hint, ok := retryAfter(err); ok {
+				wait = hint
+				if opts.MaxDelay > 0 && wait > opts.MaxDelay {
+					wait = opts.MaxDelay
+				}
+			}
+
+			// give up if the next retry would go past the time allowed
+			next := clock.Now().Add(wait)
+			if opts.MaxElapsedTime > 0 && next.Sub(started) > opts.MaxElapsedTime {
+				return out, &RetryError{Attempts: attempts, Cause: ErrRetryTimeExhausted}
+			}
+			if deadline, ok := ctx.Deadline(); ok && opts.StopAtDeadline && next.After(deadline) {
+				return out, &RetryError{Attempts: attempts, Cause: ErrRetryTimeExhausted}
+			}
+
+			// give up if other retries have used up the shared budget
+			if opts.Budget != nil && !opts.Budget.withdraw() {
+				hooksFromContext(ctx).retryDenied(ctx, retry+1, err)
+				return out, err
+			}
+
+			hooksFromContext(ctx).retry(ctx, retry+1, wait, err)
+			if opts.OnRetry != nil {
+				opts.OnRetry(retry+1, wait, out, err)
+			}
+			select {
+			case <-clock.After(wait):
+			case <-ctx.Done():
+				return out, &RetryError{Attempts: attempts, Cause: ctx.Err()}
+			}
 		}
 
 		return nil, nil
+	})
+	s.retry = opts
+	return s
+}
+
+// Returned as the cause of a RetryError when retrying stopped because MaxElapsedTime or the deadline of the context would be exceeded.
+var ErrRetryTimeExhausted = errors.New("retry time budget exhausted")
+
+// Error returned by a retry step when the action fails and will not be retried again. Unwraps to the error returned by the last attempt.
+type RetryError struct {
+	// Every attempt that failed, in order.
+	Attempts []RetryAttempt
+
+	// Reason retrying stopped before MaxRetries was reached, such as ErrRetryTimeExhausted or the error of the context when it was cancelled. Nil if the retries ran out or the error could not be retried. Matched by errors.Is.
+	Cause error
+}
+
+// An attempt made by a retry step that failed.
+type RetryAttempt struct {
+	// Attempt number, where 0 is the initial try and 1 is the first retry.
+	Attempt int
+
+	// Time the attempt started and ended, according to the clock used by the retry step.
+	Start time.Time
+	End   time.Time
+
+	// Error returned by the attempt.
+	Err error
+}
+
+func (e *RetryError) Error() string {
+	if len(e.Attempts) == 0 {
+		// cancelled before the initial try
+		return fmt.Sprintf("%v before the first attempt", e.Cause)
+	}
+	if e.Cause != nil {
+		return fmt.Sprintf("%v after %d attempt(s): %v", e.Cause, len(e.Attempts), e.Unwrap())
 	}
+	return fmt.Sprintf("failed after %d attempt(s): %v", len(e.Attempts), e.Unwrap())
+}
+
+// Returns true if the target is the cause, so errors.Is matches both the cause and the last error.
+func (e *RetryError) Is(target error) bool {
+	return e.Cause != nil && errors.Is(e.Cause, target)
+}
+
+// Returns the error returned by the last attempt.
+func (e *RetryError) Unwrap() error {
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+	return e.Attempts[len(e.Attempts)-1].Err
+}
+
+// Wraps an error to stop a retry step from retrying, i.e. for an invalid request that will never succeed. Returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// Error that will not be retried. Created with Permanent.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Returns true if the error, or any error it wraps, was created with Permanent.
+func isPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// Returns the delay requested by the error, or any error it wraps, with a RetryAfter method.
+func retryAfter(err error) (time.Duration, bool) {
+	var hint interface{ RetryAfter() time.Duration }
+	if !errors.As(err, &hint) {
+		return 0, false
+	}
+	delay := hint.RetryAfter()
+	return delay, delay > 0
+}
+
+// Passed to a backoff strategy to determine the delay before a retry.
+type Backoff struct {
+	// Retry that will be made after the delay, where 1 is the first retry.
+	Attempt int
+
+	// Delays configured with RetryOptions.
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+
+	// Delay returned by the strategy for the previous retry, limited to MaxDelay and without jitter. Set to InitialDelay for the first retry.
+	Delay time.Duration
+
+	// Returns a random time in the closed range [min, max], using the source of randomness set on RetryOptions.
+	Rand func(min time.Duration, max time.Duration) time.Duration
 }
 
 // Backoff strategy that does nothing. The delay is consistent between retries.
-func BackoffStrategyNone() func(delay time.Duration) time.Duration {
-	return func(delay time.Duration) time.Duration {
-		return delay
+func BackoffStrategyNone() func(b Backoff) time.Duration {
+	return func(b Backoff) time.Duration {
+		return b.Delay
 	}
 }
 
 // Backoff strategy that increases delay by a predefined amount after each retry.
-func BackoffStrategyLinear(increment time.Duration) func(delay time.Duration) time.Duration {
-	return func(delay time.Duration) time.Duration {
-		delay += increment
-		return delay
+func BackoffStrategyLinear(increment time.Duration) func(b Backoff) time.Duration {
+	return func(b Backoff) time.Duration {
+		if b.Attempt <= 1 {
+			return b.Delay
+		}
+		return b.Delay + increment
+	}
+}
+
+// Backoff strategy that doubles the delay after each retry.
+func BackoffStrategyExponential() func(b Backoff) time.Duration {
+	return BackoffStrategyMultiplier(2)
+}
+
+// Backoff strategy that multiplies the delay by a factor after each retry, i.e. 1.5 for a gentler increase than exponential.
+func BackoffStrategyMultiplier(multiplier float64) func(b Backoff) time.Duration {
+	return func(b Backoff) time.Duration {
+		if b.Attempt <= 1 {
+			return b.Delay
+		}
+		return scaleDuration(b.Delay, multiplier)
 	}
 }
 
-// Backoff strategy that increases delay exponentially after each retry.
-func BackoffStrategyExponential() func(delay time.Duration) time.Duration {
-	return func(delay time.Duration) time.Duration {
-		delay *= 2
-		return delay
+// Backoff strategy that increases the delay following the Fibonacci sequence, i.e. 1, 1, 2, 3 and 5 times the initial delay.
+func BackoffStrategyFibonacci() func(b Backoff) time.Duration {
+	return func(b Backoff) time.Duration {
+		prev, next := 0.0, 1.0
+		for i := 1; i < b.Attempt; i++ {
+			prev, next = next, prev+next
+		}
+		return scaleDuration(b.InitialDelay, next)
+	}
+}
+
+// Backoff strategy that picks a random delay between zero and the exponential delay, limited to MaxDelay. Spreads out retries from many callers more than a fixed jitter. See "full jitter" in https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/.
+func BackoffStrategyFullJitter() func(b Backoff) time.Duration {
+	return func(b Backoff) time.Duration {
+		ceiling := scaleDuration(b.InitialDelay, math.Pow(2, float64(b.Attempt-1)))
+		if b.MaxDelay > 0 && ceiling > b.MaxDelay {
+			ceiling = b.MaxDelay
+		}
+		return b.Rand(0, ceiling)
+	}
+}
+
+// Backoff strategy that picks a random delay between the initial delay and three times the previous delay, limited to MaxDelay. See "decorrelated jitter" in https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/.
+func BackoffStrategyDecorrelatedJitter() func(b Backoff) time.Duration {
+	return func(b Backoff) time.Duration {
+		ceiling := scaleDuration(b.Delay, 3)
+		if b.MaxDelay > 0 && ceiling > b.MaxDelay {
+			ceiling = b.MaxDelay
+		}
+		return b.Rand(b.InitialDelay, ceiling)
+	}
+}
+
+// Multiplies a duration by a factor, limited to the maximum duration instead of overflowing.
+func scaleDuration(d time.Duration, factor float64) time.Duration {
+	scaled := float64(d) * factor
+	if scaled >= math.MaxInt64 {
+		return math.MaxInt64
 	}
+	return time.Duration(scaled)
 }
 
-// Returns a random time in the closed range [min, max].
-func randDuration(min time.Duration, max time.Duration) time.Duration {
-	return time.Duration(rand.Int63n(int64(max-min+1)) + int64(min))
+type attemptKey struct{}
+
+// Returns a copy of the context that records the current retry attempt.
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// Returns the attempt of the innermost retry step that is executing, where 0 is the initial try and 1 is the first retry. Returns 0 outside of a retry step.
+func Attempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
+
+// Guards sources of randomness provided by options, which are not safe for concurrent use.
+var randLock sync.Mutex
+
+// Returns a random time in the closed range [min, max], using the global source if r is nil. Returns min if max is not greater than min.
+func randDuration(r *rand.Rand, min time.Duration, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+	if r == nil {
+		return min + randSpan(rand.Int63n, rand.Int63, max-min)
+	}
+	randLock.Lock()
+	defer randLock.Unlock()
+	return min + randSpan(r.Int63n, r.Int63, max-min)
+}
+
+// Returns a random time in the closed range [0, span]. A span too large to add one to, i.e. from a delay limited to the maximum duration, is limited to [0, math.MaxInt64].
+func randSpan(int63n func(n int64) int64, int63 func() int64, span time.Duration) time.Duration {
+	if span < 0 || span == math.MaxInt64 {
+		return time.Duration(int63())
+	}
+	return time.Duration(int63n(int64(span) + 1))
 }
@@ -1,7 +1,11 @@
 package workflow
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -15,13 +19,13 @@ func Test_Unit_Action_Retry(t *testing.T) {
 	})
 	actionErr := errors.New("test error")
 	actionWithNumErrs := func(numErrs int) Action {
-		return func(in any) (any, error) {
+		return ActionFunc(func(ctx context.Context, in any) (any, error) {
 			if numErrs <= 0 {
 				return 3, nil
 			}
 			numErrs--
 			return 5, actionErr
-		}
+		})
 	}
 
 	testCases := []struct {
@@ -122,11 +126,465 @@ func Test_Unit_Action_Retry(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// act
 			action := Retry(tc.action, tc.opts)
-			out, err := action(tc.in)
+			out, err := action.Run(context.Background(), tc.in)
 
 			// assert w
-			assert.Equal(t, tc.err, err)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
 			assert.Equal(t, tc.expected, out)
 		})
 	}
 }
+
+// Clock that records sleeps instead of sleeping.
+type sleepRecorder struct {
+	systemClock
+	sleeps []time.Duration
+}
+
+func (c *sleepRecorder) Sleep(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+}
+
+func (c *sleepRecorder) After(d time.Duration) <-chan time.Time {
+	c.Sleep(d)
+	return elapsed(c.Now())
+}
+
+// Returns a channel that has already received the time.
+func elapsed(now time.Time) <-chan time.Time {
+	c := make(chan time.Time, 1)
+	c <- now
+	return c
+}
+
+func Test_Unit_Action_Retry_Clock(t *testing.T) {
+	// arrange
+	actionErr := errors.New("test error")
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return nil, actionErr
+	})
+	optsClock := &sleepRecorder{}
+	ctxClock := &sleepRecorder{}
+
+	// act
+	_, err := Retry(action, &RetryOptions{
+		MaxRetries:      3,
+		InitialDelay:    time.Hour,
+		BackoffStrategy: BackoffStrategyLinear(time.Hour),
+		Clock:           optsClock,
+	}).Run(WithClock(context.Background(), ctxClock), nil)
+
+	// assert
+	assert.ErrorIs(t, err, actionErr)
+	assert.Equal(t, []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour}, optsClock.sleeps)
+	assert.Empty(t, ctxClock.sleeps)
+
+	// act
+	_, err = Retry(action, &RetryOptions{
+		MaxRetries:   1,
+		InitialDelay: time.Hour,
+	}).Run(WithClock(context.Background(), ctxClock), nil)
+
+	// assert
+	assert.ErrorIs(t, err, actionErr)
+	assert.Equal(t, []time.Duration{time.Hour}, ctxClock.sleeps)
+}
+
+func Test_Unit_Action_Retry_Rand(t *testing.T) {
+	// arrange
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return nil, errors.New("test error")
+	})
+	delays := func(seed int64) []time.Duration {
+		clock := &sleepRecorder{}
+		_, err := Retry(action, &RetryOptions{
+			MaxRetries:   5,
+			InitialDelay: time.Second,
+			Jitter:       time.Millisecond * 500,
+			Clock:        clock,
+			Rand:         rand.New(rand.NewSource(seed)),
+		}).Run(context.Background(), nil)
+		assert.Error(t, err)
+		return clock.sleeps
+	}
+
+	// act
+	first := delays(1)
+	second := delays(1)
+	other := delays(2)
+
+	// assert
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+	for _, v := range first {
+		assert.GreaterOrEqual(t, v, time.Millisecond*500)
+		assert.LessOrEqual(t, v, time.Millisecond*1500)
+	}
+}
+
+func Test_Unit_Action_Retry_Attempts(t *testing.T) {
+	// arrange
+	errs := []error{errors.New("error 0"), errors.New("error 1"), errors.New("error 2")}
+	var attempts []int
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		attempt := Attempt(ctx)
+		attempts = append(attempts, attempt)
+		return attempt, errs[attempt]
+	})
+
+	type retryCall struct {
+		attempt int
+		delay   time.Duration
+		out     any
+		err     error
+	}
+	var retries []retryCall
+	clock := &sleepRecorder{}
+
+	// act
+	out, err := Retry(action, &RetryOptions{
+		MaxRetries:   2,
+		InitialDelay: time.Second,
+		Clock:        clock,
+		OnRetry: func(attempt int, delay time.Duration, out any, err error) {
+			retries = append(retries, retryCall{attempt: attempt, delay: delay, out: out, err: err})
+		},
+	}).Run(context.Background(), nil)
+
+	// assert
+	assert.Equal(t, 2, out)
+	assert.Equal(t, []int{0, 1, 2}, attempts)
+	assert.Equal(t, []retryCall{
+		{attempt: 1, delay: time.Second, out: 0, err: errs[0]},
+		{attempt: 2, delay: time.Second, out: 1, err: errs[1]},
+	}, retries)
+	assert.Equal(t, 0, Attempt(context.Background()))
+
+	var retryErr *RetryError
+	assert.ErrorAs(t, err, &retryErr)
+	assert.ErrorIs(t, err, errs[2])
+	assert.NotErrorIs(t, err, errs[0])
+	assert.Equal(t, "failed after 3 attempt(s): error 2", err.Error())
+	assert.Len(t, retryErr.Attempts, 3)
+	for i, v := range retryErr.Attempts {
+		assert.Equal(t, i, v.Attempt)
+		assert.Equal(t, errs[i], v.Err)
+		assert.False(t, v.Start.IsZero())
+		assert.False(t, v.End.Before(v.Start))
+	}
+}
+
+func Test_Unit_Action_Retry_BackoffStrategies(t *testing.T) {
+	// arrange
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return nil, errors.New("test error")
+	})
+
+	testCases := []struct {
+		name     string
+		strategy func(b Backoff) time.Duration
+		maxDelay time.Duration
+		expected []time.Duration
+	}{
+		{
+			name:     "none",
+			strategy: BackoffStrategyNone(),
+			expected: []time.Duration{10, 10, 10, 10, 10},
+		},
+		{
+			name:     "linear",
+			strategy: BackoffStrategyLinear(5),
+			expected: []time.Duration{10, 15, 20, 25, 30},
+		},
+		{
+			name:     "exponential",
+			strategy: BackoffStrategyExponential(),
+			maxDelay: 100,
+			expected: []time.Duration{10, 20, 40, 80, 100},
+		},
+		{
+			name:     "multiplier",
+			strategy: BackoffStrategyMultiplier(1.5),
+			expected: []time.Duration{10, 15, 22, 33, 49},
+		},
+		{
+			name:     "fibonacci",
+			strategy: BackoffStrategyFibonacci(),
+			maxDelay: 40,
+			expected: []time.Duration{10, 10, 20, 30, 40},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := &sleepRecorder{}
+
+			// act
+			_, err := Retry(action, &RetryOptions{
+				MaxRetries:      5,
+				InitialDelay:    10,
+				MaxDelay:        tc.maxDelay,
+				BackoffStrategy: tc.strategy,
+				Clock:           clock,
+			}).Run(context.Background(), nil)
+
+			// assert
+			assert.Error(t, err)
+			assert.Equal(t, tc.expected, clock.sleeps)
+		})
+	}
+}
+
+func Test_Unit_Action_Retry_JitterStrategies(t *testing.T) {
+	// arrange
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return nil, errors.New("test error")
+	})
+	run := func(strategy func(b Backoff) time.Duration) []time.Duration {
+		clock := &sleepRecorder{}
+		_, err := Retry(action, &RetryOptions{
+			MaxRetries:      8,
+			InitialDelay:    time.Second,
+			MaxDelay:        time.Second * 20,
+			BackoffStrategy: strategy,
+			Clock:           clock,
+			Rand:            rand.New(rand.NewSource(1)),
+		}).Run(context.Background(), nil)
+		assert.Error(t, err)
+		return clock.sleeps
+	}
+
+	// act
+	full := run(BackoffStrategyFullJitter())
+	decorrelated := run(BackoffStrategyDecorrelatedJitter())
+
+	// assert
+	for i, v := range full {
+		ceiling := time.Second << i
+		if ceiling > time.Second*20 {
+			ceiling = time.Second * 20
+		}
+		assert.GreaterOrEqual(t, v, time.Duration(0))
+		assert.LessOrEqual(t, v, ceiling)
+	}
+	prev := time.Second
+	for _, v := range decorrelated {
+		assert.GreaterOrEqual(t, v, time.Second)
+		assert.LessOrEqual(t, v, min(prev*3, time.Second*20))
+		prev = v
+	}
+}
+
+func Test_Unit_Action_Retry_JitterNotNegative(t *testing.T) {
+	// arrange
+	clock := &sleepRecorder{}
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return nil, errors.New("test error")
+	})
+
+	// act
+	_, err := Retry(action, &RetryOptions{
+		MaxRetries:   50,
+		InitialDelay: time.Millisecond,
+		Jitter:       time.Second,
+		Clock:        clock,
+	}).Run(context.Background(), nil)
+
+	// assert
+	assert.Error(t, err)
+	for _, v := range clock.sleeps {
+		assert.GreaterOrEqual(t, v, time.Duration(0))
+		assert.LessOrEqual(t, v, time.Second+time.Millisecond)
+	}
+}
+
+func Test_Unit_Action_Retry_FullJitterWithoutMaxDelay(t *testing.T) {
+	// arrange
+	clock := &sleepRecorder{}
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return nil, errors.New("test error")
+	})
+
+	// act
+	_, err := Retry(action, &RetryOptions{
+		MaxRetries:      70,
+		InitialDelay:    time.Second,
+		BackoffStrategy: BackoffStrategyFullJitter(),
+		Clock:           clock,
+		Rand:            rand.New(rand.NewSource(1)),
+	}).Run(context.Background(), nil)
+
+	// assert
+	assert.Error(t, err)
+	assert.Len(t, clock.sleeps, 70)
+	for _, v := range clock.sleeps {
+		assert.GreaterOrEqual(t, v, time.Duration(0))
+	}
+	assert.Equal(t, time.Second, randDuration(nil, time.Second, time.Second))
+	assert.GreaterOrEqual(t, randDuration(nil, 0, math.MaxInt64), time.Duration(0))
+}
+
+// Error that asks to be retried after a delay.
+type retryAfterError struct {
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return "too many requests"
+}
+
+func (e *retryAfterError) RetryAfter() time.Duration {
+	return e.delay
+}
+
+func Test_Unit_Action_Retry_RetryAfter(t *testing.T) {
+	// arrange
+	errs := []error{
+		fmt.Errorf("wrapped: %w", &retryAfterError{delay: time.Minute}),
+		&retryAfterError{delay: time.Hour},
+		&retryAfterError{delay: 0},
+		errors.New("test error"),
+	}
+	calls := 0
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		err := errs[calls]
+		calls++
+		return nil, err
+	})
+	clock := &sleepRecorder{}
+
+	// act
+	_, err := Retry(action, &RetryOptions{
+		MaxRetries:   3,
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute * 10,
+		Clock:        clock,
+	}).Run(context.Background(), nil)
+
+	// assert
+	assert.ErrorIs(t, err, errs[3])
+	assert.Equal(t, []time.Duration{time.Minute, time.Minute * 10, time.Second}, clock.sleeps)
+}
+
+func Test_Unit_Action_Retry_Permanent(t *testing.T) {
+	// arrange
+	actionErr := errors.New("test error")
+	calls := 0
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		calls++
+		if calls == 2 {
+			return 5, Permanent(actionErr)
+		}
+		return nil, actionErr
+	})
+
+	// act
+	out, err := Retry(action, &RetryOptions{MaxRetries: 5}).Run(context.Background(), nil)
+
+	// assert
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 5, out)
+	assert.ErrorIs(t, err, actionErr)
+	var permanent *PermanentError
+	assert.ErrorAs(t, err, &permanent)
+	assert.Equal(t, "failed after 2 attempt(s): test error", err.Error())
+	assert.Nil(t, Permanent(nil))
+}
+
+// Clock that advances by the duration of each sleep instead of sleeping.
+type advancingClock struct {
+	systemClock
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *advancingClock) Now() time.Time {
+	return c.now
+}
+
+func (c *advancingClock) Sleep(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+func (c *advancingClock) After(d time.Duration) <-chan time.Time {
+	c.Sleep(d)
+	return elapsed(c.now)
+}
+
+func Test_Unit_Action_Retry_MaxElapsedTime(t *testing.T) {
+	// arrange
+	actionErr := errors.New("test error")
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return nil, actionErr
+	})
+	clock := &advancingClock{now: time.Now()}
+
+	// act
+	_, err := Retry(action, &RetryOptions{
+		MaxRetries:      10,
+		InitialDelay:    time.Second,
+		MaxElapsedTime:  time.Second * 10,
+		BackoffStrategy: BackoffStrategyExponential(),
+		Clock:           clock,
+	}).Run(context.Background(), nil)
+
+	// assert
+	assert.ErrorIs(t, err, ErrRetryTimeExhausted)
+	assert.ErrorIs(t, err, actionErr)
+	assert.Equal(t, "retry time budget exhausted after 4 attempt(s): test error", err.Error())
+	assert.Equal(t, []time.Duration{time.Second, time.Second * 2, time.Second * 4}, clock.sleeps)
+}
+
+func Test_Unit_Action_Retry_StopAtDeadline(t *testing.T) {
+	// arrange
+	actionErr := errors.New("test error")
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		return nil, actionErr
+	})
+	run := func(stopAtDeadline bool) (*advancingClock, error) {
+		clock := &advancingClock{now: time.Now()}
+		ctx, cancel := context.WithDeadline(context.Background(), clock.now.Add(time.Second*5))
+		defer cancel()
+		_, err := Retry(action, &RetryOptions{
+			MaxRetries:     3,
+			InitialDelay:   time.Second * 2,
+			StopAtDeadline: stopAtDeadline,
+			Clock:          clock,
+		}).Run(ctx, nil)
+		return clock, err
+	}
+
+	// act
+	stopped, stoppedErr := run(true)
+	ignored, ignoredErr := run(false)
+
+	// assert
+	assert.ErrorIs(t, stoppedErr, ErrRetryTimeExhausted)
+	assert.ErrorIs(t, stoppedErr, actionErr)
+	assert.Len(t, stopped.sleeps, 2)
+	assert.NotErrorIs(t, ignoredErr, ErrRetryTimeExhausted)
+	assert.ErrorIs(t, ignoredErr, actionErr)
+	assert.Len(t, ignored.sleeps, 3)
+}
+
+func Test_Unit_Action_Retry_Cancelled(t *testing.T) {
+	// arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	action := Retry(Do(func(in int) (int, error) {
+		panic("should not be executed")
+	}), &RetryOptions{MaxRetries: 3})
+
+	// act
+	out, err := action.Run(ctx, 1)
+
+	// assert
+	assert.Nil(t, out)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.EqualError(t, err, "context canceled before the first attempt")
+}
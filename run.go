@@ -0,0 +1,222 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status of a run started with Start.
+type RunStatus string
+
+const (
+	RunPending   RunStatus = "pending"
+	RunRunning   RunStatus = "running"
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+	RunCancelled RunStatus = "cancelled"
+)
+
+// Returns true if the run has completed with this status.
+func (s RunStatus) Done() bool {
+	return s == RunSucceeded || s == RunFailed || s == RunCancelled
+}
+
+// A step executed by a run.
+type StepRecord struct {
+	StepInfo
+
+	// Time the step started.
+	Started time.Time
+
+	// Time the step completed. Zero if it is executing.
+	Ended time.Time
+
+	// Error returned by the step, if any.
+	Err error
+}
+
+// Maximum number of steps recorded in the trace of a run, so a run that executes many steps, such as a long pipeline, cannot use unbounded memory.
+const maxTraceSteps = 10000
+
+type traceKey struct{}
+
+// Handle to an action executing in the background. Safe for concurrent use.
+type Run struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	id       string
+	workflow string
+
+	lock    sync.Mutex
+	status  RunStatus
+	started time.Time
+	ended   time.Time
+	steps   []string
+	trace   []StepRecord
+	out     any
+	err     error
+}
+
+// Executes the action in the background and returns a handle to wait for it, cancel it or check its progress. The action is executed with a context derived from ctx that is cancelled by Cancel.
+func Start(ctx context.Context, action Action, in any) *Run {
+	r := newRun(ctx)
+	go r.execute(action, in)
+	return r
+}
+
+// Creates a pending run.
+func newRun(ctx context.Context) *Run {
+	r := &Run{
+		done:   make(chan struct{}),
+		status: RunPending,
+	}
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	return r
+}
+
+// Executes the action and records the result.
+func (r *Run) execute(action Action, in any) {
+	defer r.cancel()
+
+	r.lock.Lock()
+	if r.status != RunPending {
+		// cancelled before it started
+		r.lock.Unlock()
+		return
+	}
+	r.status = RunRunning
+	r.started = clockFromContext(r.ctx).Now()
+	r.lock.Unlock()
+
+	out, err := action.Run(WithHooks(r.ctx, r.hooks()), in)
+	r.finish(out, err)
+}
+
+// Records the result of the run and notifies anyone waiting for it.
+func (r *Run) finish(out any, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.complete(out, err)
+}
+
+// Records the result of the run. The lock must be held.
+func (r *Run) complete(out any, err error) {
+	r.out, r.err = out, err
+	r.ended = clockFromContext(r.ctx).Now()
+	r.steps = nil
+	switch {
+	case err == nil:
+		r.status = RunSucceeded
+	case errors.Is(r.ctx.Err(), context.Canceled):
+		r.status = RunCancelled
+	default:
+		r.status = RunFailed
+	}
+	close(r.done)
+}
+
+// Tracks the steps that are executing and records them in the trace.
+func (r *Run) hooks() *Hooks {
+	return &Hooks{
+		OnStepStart: func(ctx context.Context, step StepInfo, in any) context.Context {
+			clock := clockFromContext(ctx)
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			r.steps = append(r.steps, step.Path)
+			if len(r.trace) >= maxTraceSteps {
+				// the step must not be mistaken for the step that contains it
+				return context.WithValue(ctx, traceKey{}, -1)
+			}
+			r.trace = append(r.trace, StepRecord{StepInfo: step, Started: clock.Now()})
+			return context.WithValue(ctx, traceKey{}, len(r.trace)-1)
+		},
+		OnStepEnd: func(ctx context.Context, step StepInfo, out any, err error) {
+			clock := clockFromContext(ctx)
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			if i := slices.Index(r.steps, step.Path); i >= 0 {
+				r.steps = slices.Delete(r.steps, i, i+1)
+			}
+			if i, ok := ctx.Value(traceKey{}).(int); ok && i >= 0 {
+				r.trace[i].Ended = clock.Now()
+				r.trace[i].Err = err
+			}
+		},
+	}
+}
+
+// Waits for the run to complete and returns the output and error of the action.
+func (r *Run) Wait() (any, error) {
+	<-r.done
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.out, r.err
+}
+
+// Cancels the context of the action. Returns immediately, without waiting for the action to return. A run that has not started yet is cancelled without executing the action.
+func (r *Run) Cancel() {
+	r.cancel()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.status == RunPending {
+		r.complete(nil, context.Canceled)
+	}
+}
+
+// Returns the ID the engine assigned to the run. Empty for a run started with Start.
+func (r *Run) ID() string {
+	return r.id
+}
+
+// Returns the name of the workflow the engine started the run from. Empty for a run started with Start.
+func (r *Run) Workflow() string {
+	return r.workflow
+}
+
+// Returns a channel that is closed once the run has completed.
+func (r *Run) Done() <-chan struct{} {
+	return r.done
+}
+
+// Returns the status of the run.
+func (r *Run) Status() RunStatus {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.status
+}
+
+// Returns the time the action started executing and the time it completed. Either is zero if it has not happened yet.
+func (r *Run) Times() (started time.Time, ended time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.started, r.ended
+}
+
+// Returns every step the run has executed or is executing, in the order they started. Only the first 10000 steps are recorded.
+func (r *Run) Trace() []StepRecord {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return slices.Clone(r.trace)
+}
+
+// Returns the paths of the steps that are executing, in the order they started. A step that contains other executing steps, such as a sequential step, is not included. More than one step is returned when steps execute in parallel. Empty if the run is not running.
+func (r *Run) CurrentSteps() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var current []string
+	for _, v := range r.steps {
+		contains := slices.ContainsFunc(r.steps, func(other string) bool {
+			return strings.HasPrefix(other, v+"/")
+		})
+		if !contains {
+			current = append(current, v)
+		}
+	}
+	return current
+}
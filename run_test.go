@@ -0,0 +1,182 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Start_Succeeded(t *testing.T) {
+	// arrange
+	action := Do(func(in int) (int, error) {
+		return in + 1, nil
+	})
+
+	// act
+	run := Start(context.Background(), action, 1)
+	out, err := run.Wait()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, out)
+	assert.Equal(t, RunSucceeded, run.Status())
+	assert.True(t, run.Status().Done())
+	assert.Empty(t, run.CurrentSteps())
+	started, ended := run.Times()
+	assert.False(t, started.IsZero())
+	assert.False(t, ended.Before(started))
+}
+
+func Test_Unit_Start_Failed(t *testing.T) {
+	// arrange
+	actionErr := errors.New("test error")
+	action := Do(func(in int) (int, error) {
+		return 0, actionErr
+	})
+
+	// act
+	run := Start(context.Background(), action, 1)
+	<-run.Done()
+
+	// assert
+	_, err := run.Wait()
+	assert.Equal(t, actionErr, err)
+	assert.Equal(t, RunFailed, run.Status())
+}
+
+func Test_Unit_Start_Cancel(t *testing.T) {
+	// arrange
+	started := make(chan struct{})
+	action := DoContext(func(ctx context.Context, in int) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	run := Start(context.Background(), action, 1)
+	<-started
+
+	// act
+	run.Cancel()
+	_, err := run.Wait()
+
+	// assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, RunCancelled, run.Status())
+}
+
+func Test_Unit_Run_CancelPending(t *testing.T) {
+	// arrange
+	run := newRun(context.Background())
+
+	// act
+	run.Cancel()
+	run.execute(Do(func(in int) (int, error) {
+		panic("should not be executed")
+	}), 1)
+
+	// assert
+	_, err := run.Wait()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, RunCancelled, run.Status())
+	started, _ := run.Times()
+	assert.True(t, started.IsZero())
+}
+
+func Test_Unit_Run_CurrentSteps(t *testing.T) {
+	// arrange
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	block := Do(func(in int) (int, error) {
+		entered <- struct{}{}
+		<-release
+		return in, nil
+	})
+	action := Name("root", Sequential(
+		Do(func(in int) (int, error) {
+			return in, nil
+		}),
+		Parallel(func(in []Result) (int, error) {
+			return 0, nil
+		}, Name("first", block), Name("second", block)),
+	))
+
+	// act
+	run := Start(context.Background(), action, 1)
+	<-entered
+	<-entered
+	steps := run.CurrentSteps()
+	status := run.Status()
+	close(release)
+	_, err := run.Wait()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, RunRunning, status)
+	assert.ElementsMatch(t, []string{"root/parallel[1]/first[0]", "root/parallel[1]/second[1]"}, steps)
+	assert.Empty(t, run.CurrentSteps())
+}
+
+func Test_Unit_Run_Trace(t *testing.T) {
+	// arrange
+	actionErr := errors.New("test error")
+	action := Name("root", Sequential(
+		Name("add1", Do(func(in int) (int, error) {
+			return in + 1, nil
+		})),
+		Name("fail", Do(func(in int) (int, error) {
+			return 0, actionErr
+		})),
+	))
+
+	// act
+	run := Start(context.Background(), action, 1)
+	run.Wait()
+	trace := run.Trace()
+
+	// assert
+	assert.Len(t, trace, 3)
+	assert.Equal(t, StepInfo{Name: "root", Kind: KindSequential, Path: "root"}, trace[0].StepInfo)
+	assert.Equal(t, StepInfo{Name: "add1", Kind: KindAction, Path: "root/add1[0]"}, trace[1].StepInfo)
+	assert.Equal(t, StepInfo{Name: "fail", Kind: KindAction, Path: "root/fail[1]"}, trace[2].StepInfo)
+	assert.Equal(t, []error{actionErr, nil, actionErr}, []error{trace[0].Err, trace[1].Err, trace[2].Err})
+	for _, v := range trace {
+		assert.False(t, v.Started.IsZero())
+		assert.False(t, v.Ended.Before(v.Started))
+	}
+}
+
+func Test_Unit_Start_CancelDuringRetry(t *testing.T) {
+	// arrange
+	actionErr := errors.New("test error")
+	calls := 0
+	scheduled := make(chan struct{})
+	action := Retry(Do(func(in int) (int, error) {
+		calls++
+		return in, actionErr
+	}), &RetryOptions{
+		MaxRetries:   3,
+		InitialDelay: time.Hour,
+		OnRetry: func(attempt int, delay time.Duration, out any, err error) {
+			close(scheduled)
+		},
+	})
+	run := Start(context.Background(), action, 1)
+	<-scheduled
+
+	// act
+	run.Cancel()
+	out, err := run.Wait()
+
+	// assert
+	var retryErr *RetryError
+	assert.ErrorAs(t, err, &retryErr)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, actionErr)
+	assert.Equal(t, "context canceled after 1 attempt(s): test error", err.Error())
+	assert.Equal(t, 1, out)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, RunCancelled, run.Status())
+}
@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// Processes each element of the sequence through the stages of a pipeline, like Pipeline, and returns a sequence of the outputs of the last stage. Elements are read from seq on another goroutine as the stages are ready for them. Each output is yielded with a nil error, and an output that is not of type R is yielded as an error. If a stage aborts or the context is cancelled, the error is yielded last.
+//
+// Breaking out of a loop over the sequence stops reading from seq, cancels the context of every element that is being processed and waits for them to return before the loop ends.
+func Stream[T any, R any](ctx context.Context, seq iter.Seq[T], opts *PipelineOptions, stages ...Stage) iter.Seq2[R, error] {
+	if opts == nil {
+		opts = &PipelineOptions{}
+	}
+
+	return func(yield func(R, error) bool) {
+		stopped := false
+		var zero R
+		err := runPipeline(ctx, stages, opts, func(yield func(any) bool) {
+			for v := range seq {
+				if !yield(v) {
+					return
+				}
+			}
+		}, func(out any) bool {
+			result, ok := out.(R)
+			if !ok && out != nil {
+				stopped = !yield(zero, fmt.Errorf("stream output must be %v, got %T", reflect.TypeFor[R](), out))
+			} else {
+				stopped = !yield(result, nil)
+			}
+			return !stopped
+		})
+		if err != nil && !stopped {
+			yield(zero, err)
+		}
+	}
+}
+
+// Processes each element of the sequence with the action, using the given number of workers, and returns a sequence of the outputs in the same order as the elements. The first error stops the sequence and is yielded last. See Stream for the details.
+func Map[T any, R any](ctx context.Context, seq iter.Seq[T], workers int, action Action) iter.Seq2[R, error] {
+	return Stream[T, R](ctx, seq, &PipelineOptions{PreserveOrder: true}, Stage{Action: action, Workers: workers})
+}
@@ -0,0 +1,132 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Map(t *testing.T) {
+	// arrange
+	action := Do(func(in int) (string, error) {
+		time.Sleep(time.Duration(5-in) * time.Millisecond)
+		return string(rune('a' + in)), nil
+	})
+
+	// act
+	var outputs []string
+	for out, err := range Map[int, string](context.Background(), slices.Values([]int{0, 1, 2, 3, 4}), 5, action) {
+		assert.NoError(t, err)
+		outputs = append(outputs, out)
+	}
+
+	// assert
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, outputs)
+}
+
+func Test_Unit_Map_Error(t *testing.T) {
+	// arrange
+	actionErr := errors.New("test error")
+	action := Do(func(in int) (int, error) {
+		if in == 3 {
+			return 0, actionErr
+		}
+		return in, nil
+	})
+
+	// act
+	var outputs []int
+	var errs []error
+	for out, err := range Map[int, int](context.Background(), slices.Values([]int{1, 2, 3, 4}), 1, action) {
+		assert.Empty(t, errs, "nothing is yielded after the error")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		outputs = append(outputs, out)
+	}
+
+	// assert
+	assert.NotContains(t, outputs, 4)
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], actionErr)
+}
+
+func Test_Unit_Stream_EarlyTermination(t *testing.T) {
+	// arrange
+	var read, cancelled, running atomic.Int32
+	seq := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			read.Add(1)
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	action := ActionFunc(func(ctx context.Context, in any) (any, error) {
+		if in.(int) == 0 {
+			// wait until the other workers are busy
+			for running.Load() < 3 {
+				time.Sleep(time.Millisecond)
+			}
+			return 0, nil
+		}
+		running.Add(1)
+		defer running.Add(-1)
+		<-ctx.Done()
+		cancelled.Add(1)
+		return nil, ctx.Err()
+	})
+
+	// act
+	var outputs []int
+	for out, err := range Stream[int, int](context.Background(), seq, nil, Stage{Action: action, Workers: 4}) {
+		assert.NoError(t, err)
+		outputs = append(outputs, out)
+		break
+	}
+
+	// assert
+	assert.Equal(t, []int{0}, outputs)
+	assert.Equal(t, int32(0), running.Load())
+	assert.GreaterOrEqual(t, cancelled.Load(), int32(3))
+	assert.Less(t, read.Load(), int32(10))
+}
+
+func Test_Unit_Stream_OutputType(t *testing.T) {
+	// arrange
+	action := Do(func(in int) (int, error) {
+		return in, nil
+	})
+
+	// act
+	var errs []error
+	for _, err := range Stream[int, string](context.Background(), slices.Values([]int{1}), nil, Stage{Action: action}) {
+		errs = append(errs, err)
+	}
+
+	// assert
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "stream output must be string, got int")
+}
+
+func Test_Unit_Pipeline_SeqInput(t *testing.T) {
+	// arrange
+	action := Pipeline(&PipelineOptions{PreserveOrder: true}, Stage{Action: Do(func(in string) (int, error) {
+		return len(in), nil
+	})})
+	seq := maps.Keys(map[string]bool{"abc": true})
+
+	// act
+	out, err := action.Run(context.Background(), seq)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []any{3}, out)
+}
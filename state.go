@@ -0,0 +1,151 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Options for configuring a state.
+type StateOptions struct {
+	// Decides the value of a key that was set by more than one branch of a parallel step. Can set to nil to use MergeLast.
+	Merge MergePolicy
+}
+
+// Values shared by every step of a run, so a step can use data produced by a step that executed long before it without passing it through every step in between. Safe for concurrent use.
+//
+// Each branch of a parallel step receives a child state. The branch can read the values of the state that contains it, but any values it sets are only visible to the branch until every branch has completed, at which point they are merged into the containing state.
+type State struct {
+	lock   sync.RWMutex
+	values map[string]any
+	parent *State
+	merge  MergePolicy
+}
+
+// Decides the value of a key that was set by more than one branch of a parallel step. Values are in the order of the branches. Return an error to fail the parallel step.
+type MergePolicy func(key string, values []any) (any, error)
+
+// Merge policy that keeps the value set by the last branch.
+func MergeLast(key string, values []any) (any, error) {
+	return values[len(values)-1], nil
+}
+
+// Merge policy that keeps the value set by the first branch.
+func MergeFirst(key string, values []any) (any, error) {
+	return values[0], nil
+}
+
+// Merge policy that fails the parallel step when branches set the same key.
+func MergeStrict(key string, values []any) (any, error) {
+	return nil, fmt.Errorf("state: key %q was set by %d branches", key, len(values))
+}
+
+// Creates an empty state. If opts is nil, keys set by more than one branch keep the value of the last branch.
+func NewState(opts *StateOptions) *State {
+	s := &State{merge: MergeLast}
+	if opts != nil && opts.Merge != nil {
+		s.merge = opts.Merge
+	}
+	return s
+}
+
+type stateKey struct{}
+
+// Returns a copy of the context with the state attached, so it can be read after the workflow completes. Otherwise, every workflow is given a new state when it starts.
+func WithState(ctx context.Context, state *State) context.Context {
+	return context.WithValue(ctx, stateKey{}, state)
+}
+
+// Returns the state attached to the context. Always returns a state within a step created by this package, otherwise nil if WithState was not called.
+func StateFromContext(ctx context.Context) *State {
+	state, _ := ctx.Value(stateKey{}).(*State)
+	return state
+}
+
+// Returns the value of a key in the state attached to the context. Returns false if the key is not set or the value is not of type T.
+func Get[T any](ctx context.Context, key string) (T, bool) {
+	v, ok := StateFromContext(ctx).Get(key)
+	value, isType := v.(T)
+	return value, ok && isType
+}
+
+// Sets the value of a key in the state attached to the context. Panics if there is no state.
+func Set[T any](ctx context.Context, key string, value T) {
+	StateFromContext(ctx).Set(key, value)
+}
+
+// Returns the value of a key, or false if it is not set. Values set by the containing states are included.
+func (s *State) Get(key string) (any, bool) {
+	for current := s; current != nil; current = current.parent {
+		current.lock.RLock()
+		v, ok := current.values[key]
+		current.lock.RUnlock()
+		if ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Sets the value of a key.
+func (s *State) Set(key string, value any) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.values == nil {
+		s.values = map[string]any{}
+	}
+	s.values[key] = value
+}
+
+// Returns a copy of every value, including the values set by the containing states.
+func (s *State) Values() map[string]any {
+	values := map[string]any{}
+	if s.parent != nil {
+		values = s.parent.Values()
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	maps.Copy(values, s.values)
+	return values
+}
+
+// Creates a state for a branch of a parallel step.
+func (s *State) child() *State {
+	return &State{parent: s, merge: s.merge}
+}
+
+// Merges the values set by each branch of a parallel step, in the order of the branches.
+func (s *State) mergeChildren(children []*State) error {
+	set := map[string][]any{}
+	for _, v := range children {
+		v.lock.RLock()
+		for key, value := range v.values {
+			set[key] = append(set[key], value)
+		}
+		v.lock.RUnlock()
+	}
+
+	// keys are merged in a consistent order so the same key fails every time, and nothing is set if any key fails
+	merged := make(map[string]any, len(set))
+	for _, key := range slices.Sorted(maps.Keys(set)) {
+		values := set[key]
+		merged[key] = values[0]
+		if len(values) > 1 {
+			value, err := s.merge(key, values)
+			if err != nil {
+				return err
+			}
+			merged[key] = value
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.values == nil {
+		s.values = map[string]any{}
+	}
+	maps.Copy(s.values, merged)
+	return nil
+}
@@ -0,0 +1,180 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func Test_Unit_State_GetAndSet(t *testing.T) {
+	// arrange
+	state := NewState(nil)
+	action := Sequential(
+		DoContext(func(ctx context.Context, in int) (int, error) {
+			Set(ctx, "first", in)
+			return in + 1, nil
+		}),
+		Do(func(in int) (int, error) {
+			return in + 1, nil
+		}),
+		DoContext(func(ctx context.Context, in int) (int, error) {
+			first, ok := Get[int](ctx, "first")
+			assert.True(t, ok)
+			return in + first, nil
+		}),
+	)
+
+	// act
+	out, err := action.Run(WithState(context.Background(), state), 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, out)
+	assert.Equal(t, map[string]any{"first": 1}, state.Values())
+}
+
+func Test_Unit_State_GetWrongType(t *testing.T) {
+	// arrange
+	ctx := WithState(context.Background(), NewState(nil))
+	Set(ctx, "key", "value")
+
+	// act
+	value, ok := Get[int](ctx, "key")
+	_, missing := Get[string](ctx, "missing")
+
+	// assert
+	assert.False(t, ok)
+	assert.Equal(t, 0, value)
+	assert.False(t, missing)
+}
+
+func Test_Unit_State_NewPerRun(t *testing.T) {
+	// arrange
+	action := DoContext(func(ctx context.Context, in int) (bool, error) {
+		_, ok := Get[int](ctx, "key")
+		Set(ctx, "key", in)
+		return ok, nil
+	})
+
+	// act
+	first, _ := action.Run(context.Background(), 1)
+	second, _ := action.Run(context.Background(), 2)
+
+	// assert
+	assert.Equal(t, false, first)
+	assert.Equal(t, false, second)
+	assert.Nil(t, StateFromContext(context.Background()))
+}
+
+func Test_Unit_State_ParallelBranches(t *testing.T) {
+	// arrange
+	state := NewState(nil)
+	state.Set("shared", "parent")
+	branch := func(name string) Action {
+		return DoContext(func(ctx context.Context, in int) (int, error) {
+			shared, _ := Get[string](ctx, "shared")
+			Set(ctx, "shared", name)
+			Set(ctx, name, shared)
+			return in, nil
+		})
+	}
+	action := Parallel(func(in []Result) (int, error) {
+		return len(in), nil
+	}, branch("a"), branch("b"), branch("c"))
+
+	// act
+	out, err := action.Run(WithState(context.Background(), state), 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, out)
+	assert.Equal(t, map[string]any{
+		"shared": "c",
+		"a":      "parent",
+		"b":      "parent",
+		"c":      "parent",
+	}, state.Values())
+}
+
+func Test_Unit_State_MergePolicies(t *testing.T) {
+	testCases := []struct {
+		name     string
+		merge    MergePolicy
+		expected map[string]any
+		err      string
+	}{
+		{name: "default", merge: nil, expected: map[string]any{"key": 2, "other": 0}},
+		{name: "last", merge: MergeLast, expected: map[string]any{"key": 2, "other": 0}},
+		{name: "first", merge: MergeFirst, expected: map[string]any{"key": 0, "other": 0}},
+		{name: "strict", merge: MergeStrict, expected: map[string]any{}, err: `state: key "key" was set by 3 branches`},
+		{name: "custom", merge: func(key string, values []any) (any, error) {
+			sum := 0
+			for _, v := range values {
+				sum += v.(int)
+			}
+			return sum, nil
+		}, expected: map[string]any{"key": 3, "other": 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// arrange
+			state := NewState(&StateOptions{Merge: tc.merge})
+			branch := func(i int) Action {
+				return DoContext(func(ctx context.Context, in int) (int, error) {
+					Set(ctx, "key", i)
+					if i == 0 {
+						Set(ctx, "other", i)
+					}
+					return in, nil
+				})
+			}
+			action := Parallel(func(in []Result) (int, error) {
+				return 0, nil
+			}, branch(0), branch(1), branch(2))
+
+			// act
+			_, err := action.Run(WithState(context.Background(), state), 1)
+
+			// assert
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, state.Values())
+		})
+	}
+}
+
+func Test_Unit_State_NestedParallel(t *testing.T) {
+	// arrange
+	state := NewState(nil)
+	set := func(key string) Action {
+		return DoContext(func(ctx context.Context, in int) (int, error) {
+			Set(ctx, key, in)
+			return in, nil
+		})
+	}
+	reduce := func(in []Result) (int, error) {
+		return 0, errors.Join(in[0].Err, in[1].Err)
+	}
+	action := Parallel(reduce,
+		Parallel(reduce, set("a"), set("b")),
+		Sequential(set("c"), DoContext(func(ctx context.Context, in int) (int, error) {
+			// values of sibling branches are not visible until the branches complete
+			_, ok := Get[int](ctx, "a")
+			Set(ctx, "sawA", ok)
+			return in, nil
+		})),
+	)
+
+	// act
+	_, err := action.Run(WithState(context.Background(), state), 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": 1, "b": 1, "c": 1, "sawA": false}, state.Values())
+}
@@ -0,0 +1,110 @@
+package workflow
+
+import (
+	"context"
+	"strconv"
+)
+
+// Identifies the type of a step in a workflow.
+type Kind string
+
+const (
+	KindAction     Kind = "action"
+	KindSequential Kind = "sequential"
+	KindParallel   Kind = "parallel"
+	KindIf         Kind = "if"
+	KindCatch      Kind = "catch"
+	KindFinally    Kind = "finally"
+	KindRetry      Kind = "retry"
+	KindNoOp       Kind = "noop"
+	KindCheckpoint Kind = "checkpoint"
+	KindDAG        Kind = "dag"
+	KindPipeline   Kind = "pipeline"
+	KindBatch      Kind = "batch"
+)
+
+// Describes a step that is being executed.
+type StepInfo struct {
+	// Name given to the step with Name. Empty if the step was not named.
+	Name string
+
+	// Kind of the step, i.e. sequential or parallel.
+	Kind Kind
+
+	// Retry attempt the step is executing under, where 0 is the initial try. Always 0 if the step is not contained in a retry.
+	Attempt int
+
+	// Position of the step in the workflow, i.e. "root/fanout[1]/parallel[0]". Each step is identified by its name, or kind if it was not named, followed by its index in the step that contains it.
+	Path string
+}
+
+// An action created by this package. Reports to any hooks attached to the context when executed.
+type step struct {
+	name     string
+	kind     Kind
+	children []Action
+	retry    *RetryOptions
+	needs    [][]string
+	run      func(ctx context.Context, in any) (any, error)
+}
+
+// Creates a step of the given kind that calls run when executed.
+func newStep(kind Kind, children []Action, run func(ctx context.Context, in any) (any, error)) *step {
+	return &step{
+		kind:     kind,
+		children: children,
+		run:      run,
+	}
+}
+
+// Executes the step and notifies any hooks.
+func (s *step) Run(ctx context.Context, in any) (any, error) {
+	if StateFromContext(ctx) == nil {
+		ctx = WithState(ctx, NewState(nil))
+	}
+
+	hooks := hooksFromContext(ctx)
+	if len(hooks) == 0 {
+		return s.run(ctx, in)
+	}
+
+	info := StepInfo{
+		Name:    s.name,
+		Kind:    s.kind,
+		Attempt: Attempt(ctx),
+		Path:    s.path(ctx),
+	}
+
+	ctx = hooks.stepStart(ctx, info, in)
+	out, err := hooks.intercept(context.WithValue(ctx, stepInfoKey{}, info), info, in, s.run)
+	hooks.stepEnd(ctx, info, out, err)
+
+	return out, err
+}
+
+type childKey struct{}
+
+// Returns the path of the step from the path of the step that contains it and the index recorded by runChild.
+func (s *step) path(ctx context.Context) string {
+	segment := s.name
+	if segment == "" {
+		segment = string(s.kind)
+	}
+	if index, ok := ctx.Value(childKey{}).(int); ok {
+		segment += "[" + strconv.Itoa(index) + "]"
+	}
+
+	parent := stepInfoFromContext(ctx)
+	if parent.Path == "" {
+		return segment
+	}
+	return parent.Path + "/" + segment
+}
+
+// Runs an action contained by a step, recording its index so the path of the action can be determined.
+func runChild(ctx context.Context, index int, child Action, in any) (any, error) {
+	if len(hooksFromContext(ctx)) > 0 {
+		ctx = context.WithValue(ctx, childKey{}, index)
+	}
+	return child.Run(ctx, in)
+}
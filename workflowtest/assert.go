@@ -0,0 +1,70 @@
+package workflowtest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Asserts that steps with the given names were started in the given order. Other steps may have been executed in between.
+func AssertCalledInOrder(t testing.TB, r *Recorder, names ...string) bool {
+	t.Helper()
+
+	called := []string{}
+	next := 0
+	for _, v := range r.Calls() {
+		if v.Step.Name == "" {
+			continue
+		}
+		called = append(called, v.Step.Name)
+		if next < len(names) && v.Step.Name == names[next] {
+			next++
+		}
+	}
+	if next < len(names) {
+		t.Errorf("expected steps to be called in order: %s\nsteps called: %s", strings.Join(names, ", "), strings.Join(called, ", "))
+		return false
+	}
+	return true
+}
+
+// Asserts that a step with the given name was executed at least once.
+func AssertCalled(t testing.TB, r *Recorder, name string) bool {
+	t.Helper()
+	if len(r.CallsTo(name)) == 0 {
+		t.Errorf("expected step %q to be called", name)
+		return false
+	}
+	return true
+}
+
+// Asserts that no step with the given name was executed.
+func AssertNotCalled(t testing.TB, r *Recorder, name string) bool {
+	t.Helper()
+	if calls := r.CallsTo(name); len(calls) > 0 {
+		t.Errorf("expected step %q not to be called, but it was called %d time(s)", name, len(calls))
+		return false
+	}
+	return true
+}
+
+// Asserts that every time the if step with the given name was executed, it took the given branch, and that it was executed at least once.
+func AssertBranchTaken(t testing.TB, r *Recorder, name string, branch bool) bool {
+	t.Helper()
+
+	taken := []bool{}
+	for _, v := range r.Branches() {
+		if v.Step.Name == name {
+			taken = append(taken, v.Branch)
+		}
+	}
+	if len(taken) == 0 {
+		t.Errorf("expected if step %q to take the %t branch, but it was not executed", name, branch)
+		return false
+	}
+	if i := slices.Index(taken, !branch); i >= 0 {
+		t.Errorf("expected if step %q to take the %t branch, but it took the %t branch on execution %d", name, branch, !branch, i+1)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package workflowtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	workflow "github.com/eleniums/go-workflow"
+	assert "github.com/stretchr/testify/require"
+)
+
+// Records failures instead of failing the test.
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func pass() workflow.Action {
+	return workflow.Do(func(in int) (int, error) {
+		return in, nil
+	})
+}
+
+func recordWorkflow(t *testing.T, in int) *Recorder {
+	action := workflow.Sequential(
+		workflow.Name("validate", pass()),
+		workflow.Name("check", workflow.If(func(in int) (bool, error) {
+			return in > 0, nil
+		}, workflow.Name("ship", pass()), workflow.Name("cancel", pass()))),
+		workflow.Name("notify", pass()),
+	)
+	ctx, recorder := Record(context.Background())
+	_, err := action.Run(ctx, in)
+	assert.NoError(t, err)
+	return recorder
+}
+
+func Test_Unit_AssertCalledInOrder(t *testing.T) {
+	// arrange
+	recorder := recordWorkflow(t, 1)
+	ft := &fakeT{}
+
+	// act
+	passed := AssertCalledInOrder(ft, recorder, "validate", "ship", "notify")
+	failed := AssertCalledInOrder(ft, recorder, "notify", "validate")
+
+	// assert
+	assert.True(t, passed)
+	assert.False(t, failed)
+	assert.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "steps called: validate, check, ship, notify")
+}
+
+func Test_Unit_AssertCalledAndNotCalled(t *testing.T) {
+	// arrange
+	recorder := recordWorkflow(t, -1)
+	ft := &fakeT{}
+
+	// act & assert
+	assert.True(t, AssertCalled(ft, recorder, "cancel"))
+	assert.True(t, AssertNotCalled(ft, recorder, "ship"))
+	assert.False(t, AssertCalled(ft, recorder, "ship"))
+	assert.False(t, AssertNotCalled(ft, recorder, "cancel"))
+	assert.Len(t, ft.errors, 2)
+}
+
+func Test_Unit_AssertBranchTaken(t *testing.T) {
+	// arrange
+	recorder := recordWorkflow(t, 1)
+	ft := &fakeT{}
+
+	// act & assert
+	assert.True(t, AssertBranchTaken(ft, recorder, "check", true))
+	assert.False(t, AssertBranchTaken(ft, recorder, "check", false))
+	assert.False(t, AssertBranchTaken(ft, recorder, "missing", true))
+	assert.Len(t, ft.errors, 2)
+	assert.Contains(t, ft.errors[1], "was not executed")
+}
@@ -0,0 +1,137 @@
+package workflowtest
+
+import (
+	"sync"
+	"time"
+
+	workflow "github.com/eleniums/go-workflow"
+)
+
+// A clock that only moves when it is advanced, so steps that wait can be tested without waiting. Safe for concurrent use.
+type FakeClock struct {
+	lock    sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+	sleeps  []time.Duration
+}
+
+// Creates a fake clock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.changed = sync.NewCond(&c.lock)
+	return c
+}
+
+// Returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// Blocks until the clock has been advanced by the duration.
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// Returns a channel that receives the time once the clock has been advanced by the duration.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	c.sleeps = append(c.sleeps, d)
+	c.lock.Unlock()
+	return c.NewTimer(d).C()
+}
+
+// Returns a timer that fires once the clock has been advanced by the duration.
+func (c *FakeClock) NewTimer(d time.Duration) workflow.Timer {
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// Moves the clock forward, firing every timer that is due in the order they are due.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// Blocks until at least n timers, including sleeps, are waiting for the clock to advance.
+func (c *FakeClock) BlockUntil(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for len(c.timers) < n {
+		c.changed.Wait()
+	}
+}
+
+// Returns the duration of every call to Sleep and After, in order.
+func (c *FakeClock) Sleeps() []time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]time.Duration{}, c.sleeps...)
+}
+
+// Fires every timer that is due. Must be called with the lock held.
+func (c *FakeClock) fire() {
+	for {
+		var next *fakeTimer
+		for _, v := range c.timers {
+			if !v.when.After(c.now) && (next == nil || v.when.Before(next.when)) {
+				next = v
+			}
+		}
+		if next == nil {
+			return
+		}
+		c.remove(next)
+		select {
+		case next.c <- c.now:
+		default:
+			// the previous time has not been received yet
+		}
+	}
+}
+
+// Removes a timer from the waiting timers. Returns false if it was not waiting. Must be called with the lock held.
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	for i, v := range c.timers {
+		if v == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.changed.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	c     chan time.Time
+	when  time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	active := c.remove(t)
+	t.when = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	c.changed.Broadcast()
+	c.fire()
+	return active
+}
@@ -0,0 +1,88 @@
+package workflowtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	workflow "github.com/eleniums/go-workflow"
+	assert "github.com/stretchr/testify/require"
+)
+
+func Test_Unit_FakeClock_Advance(t *testing.T) {
+	// arrange
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	first := clock.After(time.Second)
+	second := clock.NewTimer(2 * time.Second)
+	stopped := clock.NewTimer(time.Second)
+
+	// act
+	assert.True(t, stopped.Stop())
+	clock.Advance(time.Second)
+
+	// assert
+	assert.Equal(t, start.Add(time.Second), <-first)
+	assert.Equal(t, start.Add(time.Second), clock.Now())
+	select {
+	case <-second.C():
+		t.Fatal("timer fired early")
+	case <-stopped.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+
+	clock.Advance(time.Second)
+	assert.Equal(t, start.Add(2*time.Second), <-second.C())
+	assert.False(t, second.Stop())
+}
+
+func Test_Unit_FakeClock_Reset(t *testing.T) {
+	// arrange
+	clock := NewFakeClock(time.Time{})
+	timer := clock.NewTimer(time.Second)
+
+	// act
+	active := timer.Reset(3 * time.Second)
+	clock.Advance(2 * time.Second)
+
+	// assert
+	assert.True(t, active)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+	clock.Advance(time.Second)
+	<-timer.C()
+}
+
+func Test_Unit_FakeClock_Retry(t *testing.T) {
+	// arrange
+	clock := NewFakeClock(time.Time{})
+	mock := NewMock(Fail(errors.New("test error")), Fail(errors.New("test error")), Return(1))
+	action := workflow.Retry(mock, &workflow.RetryOptions{
+		MaxRetries:      2,
+		InitialDelay:    time.Minute,
+		BackoffStrategy: workflow.BackoffStrategyExponential(),
+	})
+
+	// act
+	done := make(chan workflow.Result)
+	go func() {
+		out, err := action.Run(workflow.WithClock(context.Background(), clock), nil)
+		done <- workflow.Result{Out: out, Err: err}
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	clock.BlockUntil(1)
+	clock.Advance(2 * time.Minute)
+	result := <-done
+
+	// assert
+	assert.NoError(t, result.Err)
+	assert.Equal(t, 1, result.Out)
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute}, clock.Sleeps())
+	assert.Equal(t, 3, mock.CallCount())
+}
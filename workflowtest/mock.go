@@ -0,0 +1,85 @@
+// Package workflowtest provides mock actions, call recording and assertions for testing workflows.
+package workflowtest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	workflow "github.com/eleniums/go-workflow"
+)
+
+// A call made to a mock or a step.
+type Call struct {
+	// Step that was called. Empty for calls recorded by a mock.
+	Step workflow.StepInfo
+
+	// Input the call was made with.
+	In any
+
+	// Output and error returned by the call.
+	Out any
+	Err error
+
+	// Time the call was made.
+	Time time.Time
+}
+
+// An action that returns scripted results on successive calls and records every call made to it.
+type Mock struct {
+	lock    sync.Mutex
+	results []workflow.Result
+	calls   []Call
+}
+
+// Creates a mock that returns the results in order, one per call. Once every result has been returned, the last result is repeated. A mock without results returns nil.
+func NewMock(results ...workflow.Result) *Mock {
+	return &Mock{results: results}
+}
+
+// Returns a result with an output and no error, for use with NewMock.
+func Return(out any) workflow.Result {
+	return workflow.Result{Out: out}
+}
+
+// Returns a result with an error and no output, for use with NewMock.
+func Fail(err error) workflow.Result {
+	return workflow.Result{Err: err}
+}
+
+// Records the call and returns the next result.
+func (m *Mock) Run(ctx context.Context, in any) (any, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var result workflow.Result
+	if len(m.results) > 0 {
+		index := len(m.calls)
+		if index >= len(m.results) {
+			index = len(m.results) - 1
+		}
+		result = m.results[index]
+	}
+
+	m.calls = append(m.calls, Call{
+		In:   in,
+		Out:  result.Out,
+		Err:  result.Err,
+		Time: time.Now(),
+	})
+	return result.Out, result.Err
+}
+
+// Returns every call made to the mock, in order.
+func (m *Mock) Calls() []Call {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]Call{}, m.calls...)
+}
+
+// Returns the number of calls made to the mock.
+func (m *Mock) CallCount() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.calls)
+}
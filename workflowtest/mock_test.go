@@ -0,0 +1,61 @@
+package workflowtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Mock_ReturnsResultsInOrder(t *testing.T) {
+	// arrange
+	mockErr := errors.New("test error")
+	mock := NewMock(Fail(mockErr), Return(1), Return(2))
+
+	// act
+	var outs []any
+	var errs []error
+	for i := 0; i < 4; i++ {
+		out, err := mock.Run(context.Background(), i)
+		outs = append(outs, out)
+		errs = append(errs, err)
+	}
+
+	// assert
+	assert.Equal(t, []any{nil, 1, 2, 2}, outs)
+	assert.Equal(t, []error{mockErr, nil, nil, nil}, errs)
+	assert.Equal(t, 4, mock.CallCount())
+}
+
+func Test_Unit_Mock_RecordsCalls(t *testing.T) {
+	// arrange
+	mock := NewMock(Return("out"))
+
+	// act
+	_, err := mock.Run(context.Background(), "first")
+	assert.NoError(t, err)
+	_, err = mock.Run(context.Background(), "second")
+	assert.NoError(t, err)
+
+	// assert
+	calls := mock.Calls()
+	assert.Len(t, calls, 2)
+	assert.Equal(t, "first", calls[0].In)
+	assert.Equal(t, "second", calls[1].In)
+	assert.Equal(t, "out", calls[1].Out)
+	assert.False(t, calls[0].Time.IsZero())
+	assert.False(t, calls[1].Time.Before(calls[0].Time))
+}
+
+func Test_Unit_Mock_NoResults(t *testing.T) {
+	// arrange
+	mock := NewMock()
+
+	// act
+	out, err := mock.Run(context.Background(), 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Nil(t, out)
+}
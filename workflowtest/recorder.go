@@ -0,0 +1,102 @@
+package workflowtest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	workflow "github.com/eleniums/go-workflow"
+)
+
+// Records every step executed with a context, along with the branches taken by if steps.
+type Recorder struct {
+	lock     sync.Mutex
+	calls    []*Call
+	branches []Branch
+}
+
+// A branch taken by an if step.
+type Branch struct {
+	// The if step.
+	Step workflow.StepInfo
+
+	// True if the condition was true and the first action was executed.
+	Branch bool
+}
+
+type callKey struct{}
+
+// Returns a copy of the context that records every step executed with it, and the recorder. Only steps created by the workflow package are recorded, so hand-written actions should be given a name with workflow.Name.
+func Record(ctx context.Context) (context.Context, *Recorder) {
+	r := &Recorder{}
+	return workflow.WithHooks(ctx, r.hooks()), r
+}
+
+// Returns hooks that record every step to the recorder.
+func (r *Recorder) hooks() *workflow.Hooks {
+	return &workflow.Hooks{
+		OnStepStart: func(ctx context.Context, step workflow.StepInfo, in any) context.Context {
+			call := &Call{Step: step, In: in, Time: time.Now()}
+			r.lock.Lock()
+			r.calls = append(r.calls, call)
+			r.lock.Unlock()
+			return context.WithValue(ctx, callKey{}, call)
+		},
+		OnStepEnd: func(ctx context.Context, step workflow.StepInfo, out any, err error) {
+			call, _ := ctx.Value(callKey{}).(*Call)
+			if call == nil {
+				return
+			}
+			r.lock.Lock()
+			call.Out = out
+			call.Err = err
+			r.lock.Unlock()
+		},
+		OnBranch: func(ctx context.Context, step workflow.StepInfo, branch bool) {
+			r.lock.Lock()
+			r.branches = append(r.branches, Branch{Step: step, Branch: branch})
+			r.lock.Unlock()
+		},
+	}
+}
+
+// Returns every step that was executed, in the order they started.
+func (r *Recorder) Calls() []Call {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	calls := make([]Call, 0, len(r.calls))
+	for _, v := range r.calls {
+		calls = append(calls, *v)
+	}
+	return calls
+}
+
+// Returns every step with the given name that was executed, in the order they started.
+func (r *Recorder) CallsTo(name string) []Call {
+	calls := []Call{}
+	for _, v := range r.Calls() {
+		if v.Step.Name == name {
+			calls = append(calls, v)
+		}
+	}
+	return calls
+}
+
+// Returns the branches taken by if steps, in the order the conditions were evaluated.
+func (r *Recorder) Branches() []Branch {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Branch{}, r.branches...)
+}
+
+// Returns a copy of the context that executes the action in place of every step with the given name. The step is still reported to hooks, but it is not executed, nor are any steps it contains.
+func Stub(ctx context.Context, name string, action workflow.Action) context.Context {
+	return workflow.WithHooks(ctx, &workflow.Hooks{
+		Intercept: func(ctx context.Context, step workflow.StepInfo, in any, next workflow.Action) (any, error) {
+			if step.Name != name {
+				return next.Run(ctx, in)
+			}
+			return action.Run(ctx, in)
+		},
+	})
+}
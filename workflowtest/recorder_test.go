@@ -0,0 +1,93 @@
+package workflowtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	workflow "github.com/eleniums/go-workflow"
+	assert "github.com/stretchr/testify/require"
+)
+
+func Test_Unit_Recorder_RecordsCalls(t *testing.T) {
+	// arrange
+	action := workflow.Name("root", workflow.Sequential(
+		workflow.Name("add1", workflow.Do(func(in int) (int, error) {
+			return in + 1, nil
+		})),
+		workflow.Name("double", workflow.Do(func(in int) (int, error) {
+			return in * 2, nil
+		})),
+	))
+	ctx, recorder := Record(context.Background())
+
+	// act
+	out, err := action.Run(ctx, 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 4, out)
+	calls := recorder.Calls()
+	assert.Len(t, calls, 3)
+	assert.Equal(t, "root", calls[0].Step.Name)
+	assert.Equal(t, "root/add1[0]", calls[1].Step.Path)
+	assert.Equal(t, 1, calls[1].In)
+	assert.Equal(t, 2, calls[1].Out)
+	assert.Equal(t, 2, calls[2].In)
+	assert.Equal(t, 4, calls[2].Out)
+	assert.Len(t, recorder.CallsTo("double"), 1)
+}
+
+func Test_Unit_Recorder_RecordsBranches(t *testing.T) {
+	// arrange
+	action := workflow.Name("check", workflow.If(func(in int) (bool, error) {
+		return in > 0, nil
+	}, workflow.NoOp(), workflow.NoOp()))
+	ctx, recorder := Record(context.Background())
+
+	// act
+	_, err := action.Run(ctx, 1)
+	assert.NoError(t, err)
+	_, err = action.Run(ctx, -1)
+	assert.NoError(t, err)
+
+	// assert
+	branches := recorder.Branches()
+	assert.Len(t, branches, 2)
+	assert.Equal(t, "check", branches[0].Step.Name)
+	assert.True(t, branches[0].Branch)
+	assert.False(t, branches[1].Branch)
+}
+
+func Test_Unit_Stub_ReplacesNamedStep(t *testing.T) {
+	// arrange
+	chargeErr := errors.New("card declined")
+	called := false
+	action := workflow.Name("order", workflow.Sequential(
+		workflow.Name("reserve", workflow.Do(func(in int) (int, error) {
+			return in + 1, nil
+		})),
+		workflow.Catch(workflow.Name("charge", workflow.Do(func(in int) (int, error) {
+			called = true
+			return in, nil
+		})), func(out any, err error) (any, error) {
+			return "refunded", nil
+		}),
+	))
+	mock := NewMock(Fail(chargeErr))
+	ctx, recorder := Record(Stub(context.Background(), "charge", mock))
+
+	// act
+	out, err := action.Run(ctx, 1)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "refunded", out)
+	assert.False(t, called)
+	assert.Equal(t, 1, mock.CallCount())
+	assert.Equal(t, 2, mock.Calls()[0].In)
+
+	calls := recorder.CallsTo("charge")
+	assert.Len(t, calls, 1)
+	assert.Equal(t, chargeErr, calls[0].Err)
+}